@@ -0,0 +1,404 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// tensor is a node of a reverse-mode automatic differentiation graph. Operations on tensors record how to
+// propagate gradients to their operands, so a component only needs its forward expression to be differentiated.
+// Elementwise operations broadcast operands whose row or column count is 1.
+type tensor struct {
+	value     denseMatrix
+	gradient  denseMatrix
+	parents   []*tensor
+	propagate func()
+}
+
+func newTensor(value denseMatrix) *tensor {
+	return &tensor{value: value}
+}
+
+func newTensorFromMatrix(m matrix) *tensor {
+	return newTensor(denseMatrixFromMatrix(m))
+}
+
+func newTensorFromRow(v vector) *tensor {
+	return newTensorFromMatrix(matrix{v})
+}
+
+// newConstantTensor creates a single value tensor that broadcasts against tensors of any shape.
+func newConstantTensor(value float64) *tensor {
+	return newTensor(denseMatrix{rows: 1, cols: 1, data: []float64{value}})
+}
+
+func newOperationTensor(value denseMatrix, parents ...*tensor) *tensor {
+	return &tensor{value: value, parents: parents}
+}
+
+func (t *tensor) shape() string {
+	return fmt.Sprintf("%dx%d", t.value.rows, t.value.cols)
+}
+
+// backward propagates gradients from t, which must hold a single value, to every tensor it was computed from.
+func (t *tensor) backward() {
+	if t.value.rows != 1 || t.value.cols != 1 {
+		panic(fmt.Sprintf("Can not back propigate from tensor with shape %s without a seed gradient", t.shape()))
+	}
+
+	t.backwardWithGradient(denseMatrix{rows: 1, cols: 1, data: []float64{1}})
+}
+
+// backwardWithGradient propagates seed, the derivative of some objective with respect to t, through the graph.
+func (t *tensor) backwardWithGradient(seed denseMatrix) {
+	if seed.rows != t.value.rows || seed.cols != t.value.cols {
+		panic(newTensorShapeError("backward", "Seed gradient shape %dx%d does not match tensor shape %s", seed.rows, seed.cols, t.shape()))
+	}
+
+	var order []*tensor = t.topologicalOrder()
+
+	for _, node := range order {
+		node.gradient = newDenseMatrix(node.value.rows, node.value.cols)
+	}
+	copy(t.gradient.data, seed.data)
+
+	for index := len(order) - 1; index >= 0; index-- {
+		if order[index].propagate != nil {
+			order[index].propagate()
+		}
+	}
+}
+
+// topologicalOrder lists every tensor t depends on, each before the tensors computed from it.
+func (t *tensor) topologicalOrder() []*tensor {
+	var order []*tensor
+	var visited map[*tensor]bool = map[*tensor]bool{}
+
+	var visit func(node *tensor)
+	visit = func(node *tensor) {
+		if visited[node] {
+			return
+		}
+
+		visited[node] = true
+		for _, parent := range node.parents {
+			visit(parent)
+		}
+
+		order = append(order, node)
+	}
+
+	visit(t)
+	return order
+}
+
+func (t *tensor) broadcastAt(rowIndex, colIndex int) int {
+	if t.value.rows == 1 {
+		rowIndex = 0
+	}
+	if t.value.cols == 1 {
+		colIndex = 0
+	}
+
+	return rowIndex*t.value.cols + colIndex
+}
+
+func broadcastDimension(a, b int, operation string) int {
+	if a == b || b == 1 {
+		return a
+	}
+	if a == 1 {
+		return b
+	}
+
+	panic(newTensorShapeError(operation, "Can not broadcast dimensions %d and %d for %s", a, b, operation))
+}
+
+// elementwise builds a broadcasting binary operation from its value and its partial derivatives.
+func elementwise(a, b *tensor, operation string, apply func(x, y float64) float64, partialX, partialY func(x, y, output float64) float64) *tensor {
+	var rows int = broadcastDimension(a.value.rows, b.value.rows, operation)
+	var cols int = broadcastDimension(a.value.cols, b.value.cols, operation)
+	var value denseMatrix = newDenseMatrix(rows, cols)
+
+	for rowIndex := 0; rowIndex < rows; rowIndex++ {
+		for colIndex := 0; colIndex < cols; colIndex++ {
+			var x float64 = a.value.data[a.broadcastAt(rowIndex, colIndex)]
+			var y float64 = b.value.data[b.broadcastAt(rowIndex, colIndex)]
+			value.data[rowIndex*cols+colIndex] = apply(x, y)
+		}
+	}
+
+	var output *tensor = newOperationTensor(value, a, b)
+	output.propagate = func() {
+		for rowIndex := 0; rowIndex < rows; rowIndex++ {
+			for colIndex := 0; colIndex < cols; colIndex++ {
+				var outputIndex int = rowIndex*cols + colIndex
+				var aIndex int = a.broadcastAt(rowIndex, colIndex)
+				var bIndex int = b.broadcastAt(rowIndex, colIndex)
+				var x float64 = a.value.data[aIndex]
+				var y float64 = b.value.data[bIndex]
+				var outputGradient float64 = output.gradient.data[outputIndex]
+				var outputValue float64 = value.data[outputIndex]
+
+				a.gradient.data[aIndex] += outputGradient * partialX(x, y, outputValue)
+				b.gradient.data[bIndex] += outputGradient * partialY(x, y, outputValue)
+			}
+		}
+	}
+
+	return output
+}
+
+// unary builds an elementwise single operand operation from its value and derivative.
+func unary(a *tensor, apply func(x float64) float64, derivative func(x, output float64) float64) *tensor {
+	var value denseMatrix = newDenseMatrix(a.value.rows, a.value.cols)
+
+	for index, x := range a.value.data {
+		value.data[index] = apply(x)
+	}
+
+	var output *tensor = newOperationTensor(value, a)
+	output.propagate = func() {
+		for index, x := range a.value.data {
+			a.gradient.data[index] += output.gradient.data[index] * derivative(x, value.data[index])
+		}
+	}
+
+	return output
+}
+
+func (t *tensor) add(other *tensor) *tensor {
+	return elementwise(t, other, "add",
+		func(x, y float64) float64 { return x + y },
+		func(x, y, output float64) float64 { return 1 },
+		func(x, y, output float64) float64 { return 1 },
+	)
+}
+
+func (t *tensor) sub(other *tensor) *tensor {
+	return elementwise(t, other, "sub",
+		func(x, y float64) float64 { return x - y },
+		func(x, y, output float64) float64 { return 1 },
+		func(x, y, output float64) float64 { return -1 },
+	)
+}
+
+func (t *tensor) mul(other *tensor) *tensor {
+	return elementwise(t, other, "mul",
+		func(x, y float64) float64 { return x * y },
+		func(x, y, output float64) float64 { return y },
+		func(x, y, output float64) float64 { return x },
+	)
+}
+
+func (t *tensor) div(other *tensor) *tensor {
+	return elementwise(t, other, "div",
+		func(x, y float64) float64 { return x / y },
+		func(x, y, output float64) float64 { return 1 / y },
+		func(x, y, output float64) float64 { return -x / (y * y) },
+	)
+}
+
+// maximum takes the larger of both operands. Ties send the gradient to other, so maximum with a constant 0
+// has the same derivative as reluActivation.
+func (t *tensor) maximum(other *tensor) *tensor {
+	return elementwise(t, other, "maximum",
+		math.Max,
+		func(x, y, output float64) float64 {
+			if x > y {
+				return 1
+			}
+			return 0
+		},
+		func(x, y, output float64) float64 {
+			if x > y {
+				return 0
+			}
+			return 1
+		},
+	)
+}
+
+func (t *tensor) neg() *tensor {
+	return unary(t,
+		func(x float64) float64 { return -x },
+		func(x, output float64) float64 { return -1 },
+	)
+}
+
+func (t *tensor) exp() *tensor {
+	return unary(t,
+		math.Exp,
+		func(x, output float64) float64 { return output },
+	)
+}
+
+func (t *tensor) log() *tensor {
+	return unary(t,
+		math.Log,
+		func(x, output float64) float64 { return 1 / x },
+	)
+}
+
+// clip limits values to [min, max]. Values outside the range receive no gradient.
+func (t *tensor) clip(min, max float64) *tensor {
+	return unary(t,
+		func(x float64) float64 { return clip(min, max, x) },
+		func(x, output float64) float64 {
+			if x < min || x > max {
+				return 0
+			}
+			return 1
+		},
+	)
+}
+
+func (t *tensor) matMul(other *tensor) *tensor {
+	if t.value.cols != other.value.rows {
+		panic(newTensorShapeError("matMul", "Can not multiply tensors with shapes %s and %s", t.shape(), other.shape()))
+	}
+
+	var value denseMatrix = newDenseMatrix(t.value.rows, other.value.cols)
+	gemm(false, false, t.value, other.value, &value)
+
+	var output *tensor = newOperationTensor(value, t, other)
+	output.propagate = func() {
+		var leftGradient denseMatrix = newDenseMatrix(t.value.rows, t.value.cols)
+		gemm(false, true, output.gradient, other.value, &leftGradient)
+
+		var rightGradient denseMatrix = newDenseMatrix(other.value.rows, other.value.cols)
+		gemm(true, false, t.value, output.gradient, &rightGradient)
+
+		for index, gradientValue := range leftGradient.data {
+			t.gradient.data[index] += gradientValue
+		}
+		for index, gradientValue := range rightGradient.data {
+			other.gradient.data[index] += gradientValue
+		}
+	}
+
+	return output
+}
+
+func (t *tensor) transpose() *tensor {
+	var output *tensor = newOperationTensor(t.value.transpose(), t)
+	output.propagate = func() {
+		var gradient denseMatrix = output.gradient.transpose()
+		for index, gradientValue := range gradient.data {
+			t.gradient.data[index] += gradientValue
+		}
+	}
+
+	return output
+}
+
+// sum adds every value into a single value tensor.
+func (t *tensor) sum() *tensor {
+	var value denseMatrix = newDenseMatrix(1, 1)
+	for _, x := range t.value.data {
+		value.data[0] += x
+	}
+
+	var output *tensor = newOperationTensor(value, t)
+	output.propagate = func() {
+		for index := range t.gradient.data {
+			t.gradient.data[index] += output.gradient.data[0]
+		}
+	}
+
+	return output
+}
+
+func (t *tensor) mean() *tensor {
+	return t.sum().div(newConstantTensor(float64(len(t.value.data))))
+}
+
+// sumRows adds the values of every row into a single column tensor.
+func (t *tensor) sumRows() *tensor {
+	var value denseMatrix = newDenseMatrix(t.value.rows, 1)
+	for rowIndex := 0; rowIndex < t.value.rows; rowIndex++ {
+		value.data[rowIndex] = vectorSum(t.value.row(rowIndex))
+	}
+
+	var output *tensor = newOperationTensor(value, t)
+	output.propagate = func() {
+		for rowIndex := 0; rowIndex < t.value.rows; rowIndex++ {
+			var gradientRow vector = t.gradient.row(rowIndex)
+			for colIndex := range gradientRow {
+				gradientRow[colIndex] += output.gradient.data[rowIndex]
+			}
+		}
+	}
+
+	return output
+}
+
+// maxRows takes the largest value of every row into a single column tensor. The gradient flows to the first maximum.
+func (t *tensor) maxRows() *tensor {
+	var value denseMatrix = newDenseMatrix(t.value.rows, 1)
+	var maxIndexes []int = make([]int, t.value.rows)
+
+	for rowIndex := 0; rowIndex < t.value.rows; rowIndex++ {
+		var row vector = t.value.row(rowIndex)
+		for colIndex, x := range row {
+			if x > row[maxIndexes[rowIndex]] {
+				maxIndexes[rowIndex] = colIndex
+			}
+		}
+
+		value.data[rowIndex] = row[maxIndexes[rowIndex]]
+	}
+
+	var output *tensor = newOperationTensor(value, t)
+	output.propagate = func() {
+		for rowIndex, colIndex := range maxIndexes {
+			t.gradient.row(rowIndex)[colIndex] += output.gradient.data[rowIndex]
+		}
+	}
+
+	return output
+}
+
+// pick selects one column per row, as chosen by indexes, into a single column tensor.
+func (t *tensor) pick(indexes []int) *tensor {
+	if len(indexes) != t.value.rows {
+		panic(newTensorShapeError("pick", "Pick index count %d does not match tensor row count %d", len(indexes), t.value.rows))
+	}
+
+	var value denseMatrix = newDenseMatrix(t.value.rows, 1)
+	for rowIndex, colIndex := range indexes {
+		if colIndex < 0 || colIndex >= t.value.cols {
+			panic(newTensorShapeError("pick", "Pick index %d is out of bounds of tensor row length %d", colIndex, t.value.cols))
+		}
+
+		value.data[rowIndex] = t.value.at(rowIndex, colIndex)
+	}
+
+	var output *tensor = newOperationTensor(value, t)
+	output.propagate = func() {
+		for rowIndex, colIndex := range indexes {
+			t.gradient.row(rowIndex)[colIndex] += output.gradient.data[rowIndex]
+		}
+	}
+
+	return output
+}
+
+func denseExpression(input *tensor, parameters []*tensor) *tensor {
+	return input.matMul(parameters[0].transpose()).add(parameters[1])
+}
+
+func reluExpression(input *tensor, parameters []*tensor) *tensor {
+	return input.maximum(newConstantTensor(0))
+}
+
+func softmaxExpression(input *tensor, parameters []*tensor) *tensor {
+	var exponentials *tensor = input.sub(input.maxRows()).exp()
+	return exponentials.div(exponentials.sumRows())
+}
+
+// crossentropyExpression returns the per-sample loss column for probabilities, clipped like crossentropy.forward.
+func crossentropyExpression(probabilities *tensor, targets []int) *tensor {
+	const safetyMargin float64 = 1e-7
+	return probabilities.pick(targets).clip(safetyMargin, 1-safetyMargin).log().neg()
+}
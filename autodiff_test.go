@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireMatrixInDelta(t *testing.T, expected, actual matrix, delta float64, message string) {
+	require.Len(t, actual, len(expected), message)
+
+	for rowIndex := range expected {
+		require.InDeltaSlice(t, expected[rowIndex], actual[rowIndex], delta, message)
+	}
+}
+
+// requireTensorGradients checks the gradients autodiff computes for every leaf against finite differences of objective.
+func requireTensorGradients(t *testing.T, leaves []*tensor, objective func() *tensor) {
+	for _, leaf := range leaves {
+		leaf.gradient = denseMatrix{}
+	}
+
+	objective().backward()
+
+	for leafIndex, leaf := range leaves {
+		// Leaves the objective does not depend on are never reached and keep no gradient
+		var analytic vector = make(vector, len(leaf.value.data))
+		copy(analytic, leaf.gradient.data)
+
+		for index := range leaf.value.data {
+			var numerical float64 = centralDifference(&leaf.value.data[index], defaultGradientCheckEpsilon, func() float64 {
+				return objective().value.data[0]
+			})
+
+			var entry gradientCheckEntry = newGradientCheckEntry(fmt.Sprintf("leaf[%d][%d]", leafIndex, index), analytic[index], numerical)
+			require.LessOrEqual(t, entry.relativeError, 1e-6, fmt.Sprintf("%s: analytic %g numerical %g", entry.name, entry.analytic, entry.numerical))
+		}
+	}
+}
+
+// neuronOrderGradients reorders the gradients of a dense expression component, which flattens all weights before
+// all biases, into the neuron by neuron order of layer so the two can be compared.
+func neuronOrderGradients(e expressionComponent) vector {
+	var gradients vector = e.getParameterGradients()
+	var weights denseMatrix = e.parameters[0].value
+	var ordered vector = make(vector, 0, len(gradients))
+
+	for neuronIndex := 0; neuronIndex < weights.rows; neuronIndex++ {
+		ordered = append(ordered, gradients[neuronIndex*weights.cols:(neuronIndex+1)*weights.cols]...)
+		ordered = append(ordered, gradients[len(weights.data)+neuronIndex])
+	}
+
+	return ordered
+}
+
+func TestTensorOperationGradients(t *testing.T) {
+	var a *tensor = newTensorFromMatrix(matrix{{0.5, -1.2, 2}, {1.5, 0.3, -0.7}})
+	var b *tensor = newTensorFromMatrix(matrix{{1.1, 0.4, -0.6}, {-0.2, 0.9, 1.3}})
+	var column *tensor = newTensorFromMatrix(matrix{{0.8}, {-1.4}})
+	var row *tensor = newTensorFromRow(vector{0.3, -0.9, 1.6})
+	var positive *tensor = newTensorFromMatrix(matrix{{0.5, 1.2, 2}, {1.5, 0.3, 0.7}})
+	var weights *tensor = newTensorFromMatrix(matrix{{0.2, 0.5}, {-0.3, 0.1}, {0.7, -0.8}})
+	var projection *tensor = newTensorFromMatrix(matrix{{0.3, -0.2, 0.9}, {0.6, 0.1, -0.5}})
+
+	var cases map[string]func() *tensor = map[string]func() *tensor{
+		"add":       func() *tensor { return a.add(b).mul(projection).sum() },
+		"add row":   func() *tensor { return a.add(row).mul(projection).sum() },
+		"sub":       func() *tensor { return a.sub(column).mul(projection).sum() },
+		"mul":       func() *tensor { return a.mul(b).mul(projection).sum() },
+		"div":       func() *tensor { return a.div(positive).mul(projection).sum() },
+		"maximum":   func() *tensor { return a.maximum(b).mul(projection).sum() },
+		"neg":       func() *tensor { return a.neg().mul(projection).sum() },
+		"exp":       func() *tensor { return a.exp().mul(projection).sum() },
+		"log":       func() *tensor { return positive.log().mul(projection).sum() },
+		"clip":      func() *tensor { return a.clip(-1, 1).mul(projection).sum() },
+		"matMul":    func() *tensor { return a.matMul(weights).sum() },
+		"transpose": func() *tensor { return a.transpose().matMul(b).mul(weights.matMul(weights.transpose())).sum() },
+		"mean":      func() *tensor { return a.mul(b).mean() },
+		"sumRows":   func() *tensor { return a.exp().sumRows().mul(column).sum() },
+		"maxRows":   func() *tensor { return a.maxRows().mul(column).sum() },
+		"pick":      func() *tensor { return a.pick([]int{2, 0}).mul(column).sum() },
+	}
+
+	for name, objective := range cases {
+		t.Run(name, func(t *testing.T) {
+			requireTensorGradients(t, []*tensor{a, b, column, row, positive, weights}, objective)
+		})
+	}
+}
+
+func TestTensorPanics(t *testing.T) {
+	var a *tensor = newTensorFromMatrix(matrix{{1, 2, 3}, {4, 5, 6}})
+
+	assert.Panics(t, func() { a.add(newTensorFromMatrix(matrix{{1, 2}, {3, 4}})) }, "Should panic when shapes can not be broadcast")
+	assert.Panics(t, func() { a.matMul(a) }, "Should panic with mismatched inner dimensions")
+	assert.Panics(t, func() { a.backward() }, "Should panic on back propigate from a tensor with more than one value")
+	assert.Panics(t, func() { a.pick([]int{0}) }, "Should panic with pick index count not matching row count")
+	assert.Panics(t, func() { a.pick([]int{0, 3}) }, "Should panic with pick index out of bounds")
+}
+
+func TestAutodiffMatchesLayer(t *testing.T) {
+	rand.Seed(4)
+	var weights matrix = randomMatrix(6, 5)
+	var biases vector = randomMatrix(1, 6)[0]
+	var inputs matrix = randomMatrix(8, 5)
+	var forwardDerivatives matrix = randomMatrix(8, 6)
+
+	var l layer = newLayerExplicit(copyMatrix(weights), biases)
+	var e expressionComponent = newDenseExpressionComponent(weights, biases)
+
+	requireMatrixInDelta(t, l.forward(inputs), e.forward(inputs), 1e-12, "Autodiff dense forward should match layer forward")
+
+	l.backward(forwardDerivatives)
+	e.backward(forwardDerivatives)
+
+	requireMatrixInDelta(t, l.getInputDerivatives(), e.getInputDerivatives(), 1e-12, "Autodiff input derivatives should match layer input derivatives")
+	require.InDeltaSlice(t, l.getParameterGradients(), neuronOrderGradients(e), 1e-12, "Autodiff parameter gradients should match layer parameter gradients")
+}
+
+func TestAutodiffMatchesRelu(t *testing.T) {
+	var inputs matrix = matrix{{1, -1, 0}, {-0.5, 2, 3}}
+	var forwardDerivatives matrix = matrix{{2, 3, 4}, {5, 6, 7}}
+
+	var r reluActivation
+	var e expressionComponent = newExpressionComponent(reluExpression)
+
+	require.Equal(t, r.forward(inputs), e.forward(inputs), "Autodiff relu forward should match relu forward")
+
+	r.backward(forwardDerivatives)
+	e.backward(forwardDerivatives)
+
+	require.Equal(t, r.getInputDerivatives(), e.getInputDerivatives(), "Autodiff relu input derivatives should match relu input derivatives")
+}
+
+func TestAutodiffMatchesSoftmax(t *testing.T) {
+	rand.Seed(6)
+	var inputs matrix = randomMatrix(5, 4)
+	var forwardDerivatives matrix = randomMatrix(5, 4)
+
+	var s softmax
+	var e expressionComponent = newExpressionComponent(softmaxExpression)
+
+	requireMatrixInDelta(t, s.forward(inputs), e.forward(inputs), 1e-12, "Autodiff softmax forward should match softmax forward")
+
+	s.backward(forwardDerivatives)
+	e.backward(forwardDerivatives)
+
+	requireMatrixInDelta(t, s.getInputDerivatives(), e.getInputDerivatives(), 1e-12, "Autodiff softmax input derivatives should match softmax input derivatives")
+}
+
+func TestAutodiffMatchesCrossentropy(t *testing.T) {
+	var inputs matrix = matrix{{0.7, 0.2, 0.1}, {0.4, 0.5, 0.1}, {0.3, 0.3, 0.4}}
+	var targets []int = []int{0, 2, 1}
+
+	var c crossentropy
+	var probabilities *tensor = newTensorFromMatrix(inputs)
+	var losses *tensor = crossentropyExpression(probabilities, targets)
+
+	require.Equal(t, c.forward(inputs, targets), vector(losses.value.data), "Autodiff crossentropy should match crossentropy forward")
+
+	c.backward()
+	losses.sum().backward()
+
+	require.Equal(t, c.getInputDerivatives(), probabilities.gradient.toMatrix(), "Autodiff crossentropy input derivatives should match crossentropy input derivatives")
+}
+
+func TestAutodiffMatchesNetwork(t *testing.T) {
+	rand.Seed(8)
+	var handwritten network = newTestNetwork()
+	var inputs matrix = randomMatrix(6, 4)
+	var targets []int = []int{0, 1, 2, 2, 1, 0}
+
+	var l1 *layer = handwritten.components[0].(*layer)
+	var l2 *denseLayer = handwritten.components[2].(*denseLayer)
+	var expressionL1 expressionComponent = newDenseExpressionComponent(newDenseLayerFromLayer(*l1).weights.toMatrix(), newDenseLayerFromLayer(*l1).biases)
+	var expressionL2 expressionComponent = newDenseExpressionComponent(l2.weights.toMatrix(), l2.biases)
+	var relu expressionComponent = newExpressionComponent(reluExpression)
+	var softmaxComponent expressionComponent = newExpressionComponent(softmaxExpression)
+	var automatic network = newNetwork(&crossentropy{}, &expressionL1, &relu, &expressionL2, &softmaxComponent)
+
+	require.InDelta(t, handwritten.forward(inputs, targets), automatic.forward(inputs, targets), 1e-12, "Autodiff network loss should match handwritten network loss")
+
+	handwritten.backward()
+	automatic.backward()
+
+	require.InDeltaSlice(t, l1.getParameterGradients(), neuronOrderGradients(expressionL1), 1e-10, "Autodiff network gradients should match handwritten network gradients for the first layer")
+	require.InDeltaSlice(t, l2.getParameterGradients(), neuronOrderGradients(expressionL2), 1e-10, "Autodiff network gradients should match handwritten network gradients for the second layer")
+}
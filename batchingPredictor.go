@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var errPredictorClosed error = errors.New("batching predictor is closed")
+
+type batchRequest struct {
+	input  vector
+	result chan batchResult
+}
+
+type batchResult struct {
+	output vector
+	err    error
+}
+
+// batchingPredictor coalesces concurrent single-sample predictions into batched network.predict calls. A batch is
+// run once it holds maxBatchSize requests or maxWait has passed since its first request arrived, whichever is
+// first, and every caller receives its own row of the output.
+type batchingPredictor struct {
+	net          *network
+	inputCount   int
+	maxBatchSize int
+	maxWait      time.Duration
+	requests     chan batchRequest
+	closed       chan struct{}
+	closeOnce    sync.Once
+	done         chan struct{}
+	requestCount int64
+	batchCount   int64
+}
+
+// newBatchingPredictor starts the goroutine that runs the batches. It returns an error for an invalid batch size or
+// wait and for a network whose input count can not be determined.
+func newBatchingPredictor(net *network, maxBatchSize int, maxWait time.Duration) (*batchingPredictor, error) {
+	if maxBatchSize <= 0 {
+		return nil, newInvalidDimensionError("max batch size", maxBatchSize, "Can not batch predictions with max batch size %d", maxBatchSize)
+	}
+
+	if maxWait < 0 {
+		return nil, fmt.Errorf("Can not batch predictions with negative max wait %s", maxWait)
+	}
+
+	inputCount, err := networkInputCount(net)
+	if err != nil {
+		return nil, err
+	}
+
+	var p *batchingPredictor = &batchingPredictor{
+		net:          net,
+		inputCount:   inputCount,
+		maxBatchSize: maxBatchSize,
+		maxWait:      maxWait,
+		requests:     make(chan batchRequest),
+		closed:       make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+
+	go p.loop()
+	return p, nil
+}
+
+// predict returns the network output for a single input. It blocks until the batch containing the input has run
+// or ctx is done.
+func (p *batchingPredictor) predict(ctx context.Context, input vector) (vector, error) {
+	if len(input) != p.inputCount {
+		return nil, newShapeMismatchError(p.inputCount, len(input), "input has %d values but the model expects %d", len(input), p.inputCount)
+	}
+
+	var request batchRequest = batchRequest{input: input, result: make(chan batchResult, 1)}
+
+	select {
+	case p.requests <- request:
+	case <-p.closed:
+		return nil, errPredictorClosed
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	select {
+	case result := <-request.result:
+		return result.output, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// close stops accepting requests. Requests already taken into a batch still receive their result. Closing more
+// than once is a no-op.
+func (p *batchingPredictor) close() {
+	p.closeOnce.Do(func() { close(p.closed) })
+	<-p.done
+}
+
+// averageBatchSize is the number of requests per batch so far.
+func (p *batchingPredictor) averageBatchSize() float64 {
+	var batches int64 = atomic.LoadInt64(&p.batchCount)
+	if batches == 0 {
+		return 0
+	}
+
+	return float64(atomic.LoadInt64(&p.requestCount)) / float64(batches)
+}
+
+func (p *batchingPredictor) loop() {
+	defer close(p.done)
+
+	for {
+		var batch []batchRequest
+
+		select {
+		case request := <-p.requests:
+			batch = append(batch, request)
+		case <-p.closed:
+			return
+		}
+
+		var timer *time.Timer = time.NewTimer(p.maxWait)
+
+	collect:
+		for len(batch) < p.maxBatchSize {
+			select {
+			case request := <-p.requests:
+				batch = append(batch, request)
+			case <-timer.C:
+				break collect
+			case <-p.closed:
+				break collect
+			}
+		}
+
+		timer.Stop()
+		p.run(batch)
+	}
+}
+
+func (p *batchingPredictor) run(batch []batchRequest) {
+	var inputs matrix = make(matrix, len(batch))
+	for index, request := range batch {
+		inputs[index] = request.input
+	}
+
+	atomic.AddInt64(&p.requestCount, int64(len(batch)))
+	atomic.AddInt64(&p.batchCount, 1)
+
+	outputs, err := p.predictBatch(inputs)
+	for index, request := range batch {
+		if err != nil {
+			request.result <- batchResult{err: err}
+			continue
+		}
+
+		request.result <- batchResult{output: outputs[index]}
+	}
+}
+
+// predictBatch turns a panic of the network into an error for every request of the batch.
+func (p *batchingPredictor) predictBatch(inputs matrix) (outputs matrix, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("batched prediction failed: %v", recovered)
+		}
+	}()
+
+	return p.net.predict(inputs), nil
+}
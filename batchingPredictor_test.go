@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBatchingTestNetwork(seed int64, inputCount, hiddenSize, classCount int) *network {
+	rand.Seed(seed)
+	var l1 denseLayer = newDenseLayer(hiddenSize, inputCount)
+	var l2 denseLayer = newDenseLayer(classCount, hiddenSize)
+	var net network = newNetwork(&crossentropy{}, &l1, &reluActivation{}, &l2, &softmax{})
+	return &net
+}
+
+func TestBatchingPredictorMatchesPredict(t *testing.T) {
+	var net *network = newBatchingTestNetwork(1, 4, 8, 3)
+	predictor, err := newBatchingPredictor(net, 8, 5*time.Millisecond)
+	require.NoError(t, err)
+	defer predictor.close()
+
+	const callers int = 50
+	var inputs matrix = randomMatrix(callers, 4)
+	var outputs matrix = make(matrix, callers)
+	var errs []error = make([]error, callers)
+	var waitGroup sync.WaitGroup
+
+	for index := range inputs {
+		waitGroup.Add(1)
+		go func(index int) {
+			defer waitGroup.Done()
+			outputs[index], errs[index] = predictor.predict(context.Background(), inputs[index])
+		}(index)
+	}
+
+	waitGroup.Wait()
+
+	for index, input := range inputs {
+		require.NoError(t, errs[index])
+		requireMatrixInDelta(t, net.predict(matrix{input}), matrix{outputs[index]}, 1e-12, "Batched prediction should match an individual prediction")
+	}
+
+	assert.Greater(t, predictor.averageBatchSize(), 1.0, "Concurrent requests should be batched together")
+}
+
+func TestBatchingPredictorFillsBatches(t *testing.T) {
+	var net *network = newBatchingTestNetwork(2, 4, 8, 3)
+	predictor, err := newBatchingPredictor(net, 4, time.Minute)
+	require.NoError(t, err)
+	defer predictor.close()
+
+	var waitGroup sync.WaitGroup
+	for index := 0; index < 8; index++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			_, err := predictor.predict(context.Background(), vector{1, 2, 3, 4})
+			assert.NoError(t, err)
+		}()
+	}
+
+	waitGroup.Wait()
+	assert.Equal(t, int64(2), predictor.batchCount, "Full batches should run without waiting for max wait")
+	assert.Equal(t, 4.0, predictor.averageBatchSize())
+}
+
+func TestBatchingPredictorRunsPartialBatchAfterMaxWait(t *testing.T) {
+	var net *network = newBatchingTestNetwork(3, 4, 8, 3)
+	predictor, err := newBatchingPredictor(net, 100, 10*time.Millisecond)
+	require.NoError(t, err)
+	defer predictor.close()
+
+	output, err := predictor.predict(context.Background(), vector{1, 2, 3, 4})
+	require.NoError(t, err)
+	assert.Len(t, output, 3)
+	assert.Equal(t, 1.0, predictor.averageBatchSize())
+}
+
+func TestBatchingPredictorErrors(t *testing.T) {
+	var net *network = newBatchingTestNetwork(4, 4, 8, 3)
+	predictor, err := newBatchingPredictor(net, 4, time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = predictor.predict(context.Background(), vector{1, 2})
+	var shapeErr *shapeMismatchError
+	assert.True(t, errors.As(err, &shapeErr), "Inputs of the wrong length should be rejected before batching")
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = predictor.predict(ctx, vector{1, 2, 3, 4})
+	assert.Equal(t, context.Canceled, err)
+
+	predictor.close()
+	_, err = predictor.predict(context.Background(), vector{1, 2, 3, 4})
+	assert.Equal(t, errPredictorClosed, err)
+	assert.NotPanics(t, predictor.close, "Closing twice should be a no-op")
+
+	var relu network = newNetwork(&crossentropy{}, &reluActivation{})
+	_, err = newBatchingPredictor(&relu, 4, time.Millisecond)
+	assert.Error(t, err, "The first component has to be a layer")
+
+	_, err = newBatchingPredictor(net, 0, time.Millisecond)
+	var dimensionErr *invalidDimensionError
+	assert.True(t, errors.As(err, &dimensionErr), "Max batch size 0 should be rejected")
+
+	_, err = newBatchingPredictor(net, 1, -time.Millisecond)
+	assert.Error(t, err, "A negative max wait should be rejected")
+}
+
+// benchmarkConcurrentPredictions runs predict from many goroutines and reports the median and 99th percentile
+// latency of a single call.
+func benchmarkConcurrentPredictions(b *testing.B, predict func(input vector) vector) {
+	var input vector = randomMatrix(1, 64)[0]
+	var latencyMutex sync.Mutex
+	var latencies []time.Duration = make([]time.Duration, 0, b.N)
+
+	b.SetParallelism(16)
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var start time.Time = time.Now()
+			predict(input)
+			var latency time.Duration = time.Since(start)
+
+			latencyMutex.Lock()
+			latencies = append(latencies, latency)
+			latencyMutex.Unlock()
+		}
+	})
+	b.StopTimer()
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	b.ReportMetric(float64(latencies[len(latencies)/2].Microseconds()), "p50-µs")
+	b.ReportMetric(float64(latencies[len(latencies)*99/100].Microseconds()), "p99-µs")
+}
+
+func BenchmarkUnbatchedPredict(b *testing.B) {
+	var net *network = newBatchingTestNetwork(5, 64, 256, 10)
+
+	benchmarkConcurrentPredictions(b, func(input vector) vector {
+		return net.predict(matrix{input})[0]
+	})
+}
+
+func BenchmarkBatchingPredictor(b *testing.B) {
+	for _, maxBatchSize := range []int{8, 32, 128} {
+		b.Run(fmt.Sprintf("maxBatchSize=%d", maxBatchSize), func(b *testing.B) {
+			var net *network = newBatchingTestNetwork(5, 64, 256, 10)
+			predictor, err := newBatchingPredictor(net, maxBatchSize, 200*time.Microsecond)
+			require.NoError(b, err)
+			defer predictor.close()
+
+			benchmarkConcurrentPredictions(b, func(input vector) vector {
+				output, _ := predictor.predict(context.Background(), input)
+				return output
+			})
+			b.ReportMetric(predictor.averageBatchSize(), "requests/batch")
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// callback hooks into a trainer. Returning an error from any hook aborts training with that error.
+type callback interface {
+	onTrainBegin(state *trainingState) error
+	onTrainEnd(state *trainingState) error
+	onEpochBegin(state *trainingState) error
+	onEpochEnd(state *trainingState) error
+	onBatchBegin(state *trainingState) error
+	onBatchEnd(state *trainingState) error
+}
+
+// baseCallback implements every hook as a no-op so callbacks only need to implement the hooks they use.
+type baseCallback struct{}
+
+func (baseCallback) onTrainBegin(state *trainingState) error { return nil }
+func (baseCallback) onTrainEnd(state *trainingState) error   { return nil }
+func (baseCallback) onEpochBegin(state *trainingState) error { return nil }
+func (baseCallback) onEpochEnd(state *trainingState) error   { return nil }
+func (baseCallback) onBatchBegin(state *trainingState) error { return nil }
+func (baseCallback) onBatchEnd(state *trainingState) error   { return nil }
+
+// loggingCallback prints the learning rate and average loss every logRate epochs, starting with the first.
+type loggingCallback struct {
+	baseCallback
+	writer  io.Writer
+	logRate int
+}
+
+func newLoggingCallback(writer io.Writer, logRate int) *loggingCallback {
+	if logRate <= 0 {
+		panic(fmt.Sprintf("Can not log with log rate %d", logRate))
+	}
+
+	return &loggingCallback{writer: writer, logRate: logRate}
+}
+
+func (l *loggingCallback) onEpochEnd(state *trainingState) error {
+	if (state.epoch-1)%l.logRate != 0 {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(l.writer, "Learning Rate: %f\nEpoch %d Average Loss: %f\n\n", state.learningRate, state.epoch, state.loss)
+	return err
+}
+
+const (
+	earlyStoppingModeMin string = "min"
+	earlyStoppingModeMax string = "max"
+)
+
+// earlyStoppingConfig selects the metric early stopping watches. An empty mode maximizes metrics whose name ends
+// in accuracy and minimizes everything else. An improvement has to beat the best value by more than minDelta.
+type earlyStoppingConfig struct {
+	monitor            string
+	mode               string
+	patience           int
+	minDelta           float64
+	restoreBestWeights bool
+}
+
+// earlyStoppingCallback stops training once the monitored metric has not improved for patience epochs.
+// With restoreBestWeights it snapshots the parameters of the best epoch and restores them when training ends.
+// Validation metrics are measured after the epoch and belong to the parameters it ended with. Training metrics
+// are measured during the epoch, so they only belong to one set of parameters, the ones the epoch started with,
+// when the epoch is a single batch; restoring weights for a training metric fails with mini-batches.
+type earlyStoppingCallback struct {
+	baseCallback
+	config            earlyStoppingConfig
+	bestValue         float64
+	bestEpoch         int
+	waitedCount       int
+	stopped           bool
+	bestWeights       []vector
+	epochStartWeights []vector
+}
+
+func newEarlyStoppingCallback(config earlyStoppingConfig) *earlyStoppingCallback {
+	if config.patience <= 0 {
+		panic(fmt.Sprintf("Can not stop early with patience %d", config.patience))
+	}
+
+	if config.minDelta < 0 {
+		panic(fmt.Sprintf("Can not stop early with negative min delta %f", config.minDelta))
+	}
+
+	if config.monitor == "" {
+		config.monitor = "loss"
+	}
+
+	if config.mode == "" {
+		config.mode = earlyStoppingModeMin
+		if strings.HasSuffix(config.monitor, "accuracy") {
+			config.mode = earlyStoppingModeMax
+		}
+	}
+
+	if config.mode != earlyStoppingModeMin && config.mode != earlyStoppingModeMax {
+		panic(fmt.Sprintf("Unknown early stopping mode %q", config.mode))
+	}
+
+	return &earlyStoppingCallback{config: config}
+}
+
+func (e *earlyStoppingCallback) monitorsValidation() bool {
+	return strings.HasPrefix(e.config.monitor, validationMetricPrefix)
+}
+
+func (e *earlyStoppingCallback) improves(value float64) bool {
+	if e.config.mode == earlyStoppingModeMax {
+		return value > e.bestValue+e.config.minDelta
+	}
+
+	return value < e.bestValue-e.config.minDelta
+}
+
+// onTrainBegin rejects restoring the best weights for a training metric unless every epoch is a single batch: the
+// metric is averaged over weights that change during the epoch, so no weights match it. Streamed datasets are
+// rejected too, since their batch count is not known before training.
+func (e *earlyStoppingCallback) onTrainBegin(state *trainingState) error {
+	if e.config.restoreBestWeights && !e.monitorsValidation() && state.batchesPerEpoch != 1 {
+		return fmt.Errorf("early stopping can not restore the best weights for training metric %q without exactly one batch per epoch, since the metric is averaged over changing weights. Monitor a validation metric or train on the full batch in memory", e.config.monitor)
+	}
+
+	e.bestValue = math.Inf(1)
+	if e.config.mode == earlyStoppingModeMax {
+		e.bestValue = math.Inf(-1)
+	}
+
+	e.bestEpoch = 0
+	e.waitedCount = 0
+	e.stopped = false
+	e.bestWeights = nil
+	e.epochStartWeights = nil
+	return nil
+}
+
+func (e *earlyStoppingCallback) onEpochBegin(state *trainingState) error {
+	if e.config.restoreBestWeights && !e.monitorsValidation() {
+		e.epochStartWeights = snapshotParameters(state.net)
+	}
+
+	return nil
+}
+
+func (e *earlyStoppingCallback) onEpochEnd(state *trainingState) error {
+	value, ok := state.metrics[e.config.monitor]
+	if !ok {
+		return fmt.Errorf("early stopping monitors metric %q which is not reported during training", e.config.monitor)
+	}
+
+	if e.improves(value) {
+		e.bestValue = value
+		e.bestEpoch = state.epoch
+		e.waitedCount = 0
+
+		if e.config.restoreBestWeights && e.monitorsValidation() {
+			e.bestWeights = snapshotParameters(state.net)
+		} else if e.config.restoreBestWeights {
+			e.bestWeights = e.epochStartWeights
+		}
+
+		return nil
+	}
+
+	e.waitedCount++
+	if e.waitedCount >= e.config.patience {
+		e.stopped = true
+		state.stopTraining = true
+	}
+
+	return nil
+}
+
+func (e *earlyStoppingCallback) onTrainEnd(state *trainingState) error {
+	if e.config.restoreBestWeights && e.bestWeights != nil {
+		restoreParameters(state.net, e.bestWeights)
+	}
+
+	return nil
+}
+
+func (e *earlyStoppingCallback) stoppedTraining() bool {
+	return e.stopped
+}
+
+type earlyStoppingCheckpoint struct {
+	BestValue         float64
+	BestEpoch         int
+	WaitedCount       int
+	Stopped           bool
+	BestWeights       []vector
+	EpochStartWeights []vector
+}
+
+func (e *earlyStoppingCallback) checkpointState() ([]byte, error) {
+	var buffer bytes.Buffer
+	var err error = gob.NewEncoder(&buffer).Encode(earlyStoppingCheckpoint{
+		BestValue:         e.bestValue,
+		BestEpoch:         e.bestEpoch,
+		WaitedCount:       e.waitedCount,
+		Stopped:           e.stopped,
+		BestWeights:       e.bestWeights,
+		EpochStartWeights: e.epochStartWeights,
+	})
+
+	return buffer.Bytes(), err
+}
+
+func (e *earlyStoppingCallback) restoreCheckpointState(data []byte) error {
+	var checkpoint earlyStoppingCheckpoint
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&checkpoint); err != nil {
+		return err
+	}
+
+	e.bestValue = checkpoint.BestValue
+	e.bestEpoch = checkpoint.BestEpoch
+	e.waitedCount = checkpoint.WaitedCount
+	e.stopped = checkpoint.Stopped
+	e.bestWeights = checkpoint.BestWeights
+	e.epochStartWeights = checkpoint.EpochStartWeights
+	return nil
+}
+
+// checkpointCallback saves the network every saveRate epochs and once more when training ends. It only keeps the
+// model for later use; resumable training checkpoints are written by the trainer itself, see trainingConfig.
+type checkpointCallback struct {
+	baseCallback
+	path     string
+	saveRate int
+}
+
+func newCheckpointCallback(path string, saveRate int) *checkpointCallback {
+	if saveRate <= 0 {
+		panic(fmt.Sprintf("Can not checkpoint with save rate %d", saveRate))
+	}
+
+	return &checkpointCallback{path: path, saveRate: saveRate}
+}
+
+func (c *checkpointCallback) onEpochEnd(state *trainingState) error {
+	if state.epoch%c.saveRate != 0 {
+		return nil
+	}
+
+	return saveNetwork(state.net, c.path)
+}
+
+func (c *checkpointCallback) onTrainEnd(state *trainingState) error {
+	return saveNetwork(state.net, c.path)
+}
+
+// lossHistoryCallback records the loss of every epoch and every batch.
+type lossHistoryCallback struct {
+	baseCallback
+	epochLosses []float64
+	batchLosses []float64
+}
+
+func (l *lossHistoryCallback) onTrainBegin(state *trainingState) error {
+	l.epochLosses = nil
+	l.batchLosses = nil
+	return nil
+}
+
+func (l *lossHistoryCallback) onBatchEnd(state *trainingState) error {
+	l.batchLosses = append(l.batchLosses, state.loss)
+	return nil
+}
+
+func (l *lossHistoryCallback) onEpochEnd(state *trainingState) error {
+	l.epochLosses = append(l.epochLosses, state.loss)
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallbackConstructorPanics(t *testing.T) {
+	assert.Panics(t, func() { newLoggingCallback(&bytes.Buffer{}, 0) }, "Should panic with log rate 0")
+	assert.Panics(t, func() { newEarlyStoppingCallback(earlyStoppingConfig{patience: 0}) }, "Should panic with patience 0")
+	assert.Panics(t, func() { newEarlyStoppingCallback(earlyStoppingConfig{patience: 1, minDelta: -1}) }, "Should panic with negative min delta")
+	assert.Panics(t, func() { newEarlyStoppingCallback(earlyStoppingConfig{patience: 1, mode: "sideways"}) }, "Should panic with unknown mode")
+	assert.Panics(t, func() { newCheckpointCallback("model.json", 0) }, "Should panic with save rate 0")
+}
+
+func TestLoggingCallback(t *testing.T) {
+	var buffer bytes.Buffer
+	var logger *loggingCallback = newLoggingCallback(&buffer, 2)
+
+	for epoch := 1; epoch <= 3; epoch++ {
+		require.NoError(t, logger.onEpochEnd(&trainingState{epoch: epoch, loss: float64(epoch) / 10, learningRate: 0.5}))
+	}
+
+	require.Equal(t, "Learning Rate: 0.500000\nEpoch 1 Average Loss: 0.100000\n\nLearning Rate: 0.500000\nEpoch 3 Average Loss: 0.300000\n\n", buffer.String(), "Wrong log output")
+}
+
+func TestEarlyStoppingCallback(t *testing.T) {
+	var earlyStopping *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{patience: 2})
+	var state *trainingState = &trainingState{metrics: map[string]float64{}}
+	require.NoError(t, earlyStopping.onTrainBegin(state))
+
+	for epoch, loss := range []float64{1, 0.5, 0.6, 0.4, 0.45} {
+		state.epoch = epoch + 1
+		state.metrics["loss"] = loss
+		require.NoError(t, earlyStopping.onEpochEnd(state))
+		require.False(t, state.stopTraining, "Should not stop before patience runs out")
+	}
+
+	state.metrics["loss"] = 0.41
+	require.NoError(t, earlyStopping.onEpochEnd(state))
+	require.True(t, state.stopTraining, "Should stop after patience epochs without improvement")
+	require.Equal(t, 4, earlyStopping.bestEpoch, "Wrong best epoch")
+}
+
+func TestEarlyStoppingMinDeltaAndMode(t *testing.T) {
+	var minDelta *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{patience: 2, minDelta: 0.1})
+	var state *trainingState = &trainingState{metrics: map[string]float64{}}
+	require.NoError(t, minDelta.onTrainBegin(state))
+
+	for _, loss := range []float64{1, 0.95, 0.92} {
+		state.metrics["loss"] = loss
+		require.NoError(t, minDelta.onEpochEnd(state))
+	}
+	require.True(t, state.stopTraining, "Improvements smaller than min delta should not reset patience")
+
+	var accuracy *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{monitor: "val_accuracy", patience: 1})
+	require.Equal(t, earlyStoppingModeMax, accuracy.config.mode, "Accuracy metrics should be maximized by default")
+
+	state = &trainingState{metrics: map[string]float64{}}
+	require.NoError(t, accuracy.onTrainBegin(state))
+	for _, value := range []float64{0.5, 0.7} {
+		state.metrics["val_accuracy"] = value
+		require.NoError(t, accuracy.onEpochEnd(state))
+		require.False(t, state.stopTraining, "Rising accuracy should count as an improvement")
+	}
+}
+
+func TestEarlyStoppingMissingMetric(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var earlyStopping *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{monitor: "val_loss", patience: 1})
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 3, learningRateStart: 0.1}, earlyStopping)
+
+	require.Error(t, tr.train(inputs, targets), "Monitoring validation loss without validation data should fail")
+}
+
+func TestEarlyStoppingStopsTrainer(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var history *lossHistoryCallback = &lossHistoryCallback{}
+	var earlyStopping *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{patience: 3, restoreBestWeights: true})
+
+	// A learning rate this large makes the loss diverge so the patience runs out almost immediately
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 1000, learningRateStart: 1000}, earlyStopping, history)
+
+	require.NoError(t, tr.train(inputs, targets))
+	require.True(t, earlyStopping.stopped, "Early stopping should have stopped training")
+	require.Less(t, len(history.epochLosses), 1000, "Early stopping should end training before the last epoch")
+	require.Equal(t, earlyStopping.bestValue, net.forward(inputs, targets), "The restored weights should reproduce the best training loss")
+}
+
+func TestEarlyStoppingRestoresBestValidationWeights(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var earlyStopping *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{monitor: "val_loss", patience: 2, restoreBestWeights: true})
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 1000, learningRateStart: 5}, earlyStopping)
+	tr.setValidationData(inputs[:3], targets[:3])
+
+	require.NoError(t, tr.train(inputs, targets))
+	require.True(t, earlyStopping.stopped, "Early stopping should have stopped training")
+	require.Equal(t, earlyStopping.bestValue, net.forward(inputs[:3], targets[:3]), "The restored weights should reproduce the best validation loss")
+}
+
+func TestCheckpointCallback(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var path string = filepath.Join(t.TempDir(), "model.json")
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 3, learningRateStart: 0.1}, newCheckpointCallback(path, 2))
+
+	require.NoError(t, tr.train(inputs, targets))
+
+	loaded, err := loadNetwork(path)
+	require.NoError(t, err)
+	require.Equal(t, net.components[0].(*layer).getParameters(), loaded.components[0].(*layer).getParameters(), "Checkpoint should hold the final weights")
+}
+
+func TestCheckpointCallbackReportsErrors(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var path string = filepath.Join(t.TempDir(), "missing", "model.json")
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 3, learningRateStart: 0.1}, newCheckpointCallback(path, 1))
+
+	require.Error(t, tr.train(inputs, targets), "Training should fail when a checkpoint can not be written")
+}
+
+func TestLossHistoryCallback(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var history *lossHistoryCallback = &lossHistoryCallback{}
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 4, batchSize: 3, learningRateStart: 0.1}, history)
+
+	require.NoError(t, tr.train(inputs, targets))
+	require.Len(t, history.epochLosses, 4, "Should record one loss per epoch")
+	require.Len(t, history.batchLosses, 8, "Should record one loss per batch")
+	require.InDelta(t, (history.batchLosses[0]+history.batchLosses[1])/2, history.epochLosses[0], 1e-12, "Epoch loss should average the batch losses")
+}
+
+func TestEarlyStoppingRejectsRestoringTrainingMetricWithMiniBatches(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var earlyStopping *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{patience: 3, restoreBestWeights: true})
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 10, batchSize: 2, learningRateStart: 0.1}, earlyStopping)
+	var initial []vector = snapshotParameters(&net)
+
+	require.Error(t, tr.train(inputs, targets), "Restoring weights for a training metric should fail with mini-batches")
+	assert.Equal(t, initial, snapshotParameters(&net), "The config should be rejected before any training")
+	assert.Zero(t, tr.completedEpochs)
+
+	net = newTrainingTestNetwork(1)
+	earlyStopping = newEarlyStoppingCallback(earlyStoppingConfig{monitor: "val_loss", patience: 3, restoreBestWeights: true})
+	tr = newTrainer(&net, trainingConfig{epochs: 10, batchSize: 2, learningRateStart: 0.1}, earlyStopping)
+	tr.setValidationData(inputs, targets)
+
+	require.NoError(t, tr.train(inputs, targets), "Restoring weights for a validation metric should work with mini-batches")
+}
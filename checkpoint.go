@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// splitMix64Source is a rand.Source64 whose whole state is a single integer, so it can be saved in checkpoints
+// and restored to continue the exact same random sequence.
+type splitMix64Source struct {
+	state uint64
+}
+
+func newSplitMix64Source(seed int64) *splitMix64Source {
+	var source *splitMix64Source = &splitMix64Source{}
+	source.Seed(seed)
+	return source
+}
+
+func (s *splitMix64Source) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+func (s *splitMix64Source) Uint64() uint64 {
+	s.state += 0x9E3779B97F4A7C15
+
+	var z uint64 = s.state
+	z = (z ^ (z >> 30)) * 0xBF58476D1CE4E5B9
+	z = (z ^ (z >> 27)) * 0x94D049BB133111EB
+	return z ^ (z >> 31)
+}
+
+func (s *splitMix64Source) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Seed purposes keep the random streams drawn from one user seed apart, so for example the weights of the first
+// fold are not drawn from the stream that shuffled the folds.
+const (
+	seedPurposeSplit uint64 = iota + 1
+	seedPurposeSampling
+	seedPurposeInitialization
+)
+
+// deriveSeed mixes purpose into seed and scrambles the result with one SplitMix64 step.
+func deriveSeed(seed int64, purpose uint64) int64 {
+	var source splitMix64Source = splitMix64Source{state: uint64(seed) ^ purpose*0xD1B54A32D192ED03}
+	return int64(source.Uint64())
+}
+
+// checkpointableCallback is implemented by callbacks whose state has to survive a resumed training run.
+type checkpointableCallback interface {
+	checkpointState() ([]byte, error)
+	restoreCheckpointState(data []byte) error
+}
+
+// stoppingCallback is implemented by checkpointable callbacks that stop training. After resuming, training does
+// not continue if one of them had already stopped the interrupted run.
+type stoppingCallback interface {
+	stoppedTraining() bool
+}
+
+type optimizerCheckpoint struct {
+	LearningRate float64
+	Momentum     float64
+	Velocities   []vector
+}
+
+type schedulerCheckpoint struct {
+	LearningRateStart float64
+	LearningRateDecay float64
+}
+
+// trainingCheckpoint holds everything needed to continue training exactly where it stopped.
+// Callback states are stored by the callback's position in the trainer's callback list.
+type trainingCheckpoint struct {
+	CompletedEpochs int
+	Parameters      []vector
+	Optimizer       optimizerCheckpoint
+	Scheduler       schedulerCheckpoint
+	RandomState     uint64
+	CallbackStates  map[int][]byte
+}
+
+func (t *trainer) checkpoint() (trainingCheckpoint, error) {
+	var checkpoint trainingCheckpoint = trainingCheckpoint{
+		CompletedEpochs: t.completedEpochs,
+		Parameters:      snapshotParameters(t.net),
+		Optimizer: optimizerCheckpoint{
+			LearningRate: t.optimizer.learningRate,
+			Momentum:     t.optimizer.momentum,
+			Velocities:   t.optimizer.velocities,
+		},
+		Scheduler: schedulerCheckpoint{
+			LearningRateStart: t.scheduler.learningRateStart,
+			LearningRateDecay: t.scheduler.learningRateDecay,
+		},
+		RandomState:    t.randomSource.state,
+		CallbackStates: map[int][]byte{},
+	}
+
+	for index, c := range t.callbacks {
+		if checkpointable, ok := c.(checkpointableCallback); ok {
+			state, err := checkpointable.checkpointState()
+			if err != nil {
+				return trainingCheckpoint{}, fmt.Errorf("can not checkpoint callback %d: %w", index, err)
+			}
+
+			checkpoint.CallbackStates[index] = state
+		}
+	}
+
+	return checkpoint, nil
+}
+
+func (t *trainer) restoreCheckpoint(checkpoint trainingCheckpoint) error {
+	var trainables []trainable = t.net.trainables()
+
+	if len(checkpoint.Parameters) != len(trainables) {
+		return fmt.Errorf("checkpoint has parameters for %d trainable components but the network has %d", len(checkpoint.Parameters), len(trainables))
+	}
+
+	for index, trainableComponent := range trainables {
+		if len(checkpoint.Parameters[index]) != trainableComponent.parameterCount() {
+			return fmt.Errorf("checkpoint has %d parameters for trainable component %d but it has %d", len(checkpoint.Parameters[index]), index, trainableComponent.parameterCount())
+		}
+	}
+
+	for index, c := range t.callbacks {
+		state, ok := checkpoint.CallbackStates[index]
+		checkpointable, isCheckpointable := c.(checkpointableCallback)
+
+		if ok && isCheckpointable {
+			if err := checkpointable.restoreCheckpointState(state); err != nil {
+				return fmt.Errorf("can not restore callback %d: %w", index, err)
+			}
+		}
+	}
+
+	restoreParameters(t.net, checkpoint.Parameters)
+	t.completedEpochs = checkpoint.CompletedEpochs
+	t.optimizer = sgdOptimizer{
+		learningRate: checkpoint.Optimizer.LearningRate,
+		momentum:     checkpoint.Optimizer.Momentum,
+		velocities:   checkpoint.Optimizer.Velocities,
+	}
+	t.scheduler = inverseTimeDecay{
+		learningRateStart: checkpoint.Scheduler.LearningRateStart,
+		learningRateDecay: checkpoint.Scheduler.LearningRateDecay,
+	}
+	t.randomSource.state = checkpoint.RandomState
+
+	return nil
+}
+
+func (t *trainer) saveCheckpoint(path string) error {
+	checkpoint, err := t.checkpoint()
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	if err = gob.NewEncoder(&buffer).Encode(checkpoint); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, buffer.Bytes())
+}
+
+func loadCheckpoint(path string) (trainingCheckpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return trainingCheckpoint{}, err
+	}
+
+	var checkpoint trainingCheckpoint
+	if err = gob.NewDecoder(bytes.NewReader(data)).Decode(&checkpoint); err != nil {
+		return trainingCheckpoint{}, fmt.Errorf("can not parse checkpoint file %s: %w", path, err)
+	}
+
+	return checkpoint, nil
+}
+
+// resumeFromCheckpoint restores the trainer from the checkpoint at path. A missing checkpoint is not an error;
+// training then simply starts from the beginning.
+func (t *trainer) resumeFromCheckpoint(path string) error {
+	checkpoint, err := loadCheckpoint(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return t.restoreCheckpoint(checkpoint)
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitMix64SourceRestoresSequence(t *testing.T) {
+	var source *splitMix64Source = newSplitMix64Source(42)
+	var random *rand.Rand = rand.New(source)
+	random.Intn(100)
+
+	var savedState uint64 = source.state
+	var expected []int = random.Perm(20)
+
+	source.state = savedState
+	require.Equal(t, expected, random.Perm(20), "Restoring the state should repeat the random sequence")
+}
+
+func TestDeriveSeedSeparatesPurposes(t *testing.T) {
+	var seeds map[int64]bool = map[int64]bool{}
+	for seed := int64(0); seed < 50; seed++ {
+		for _, purpose := range []uint64{seedPurposeSplit, seedPurposeSampling, seedPurposeInitialization} {
+			var derived int64 = deriveSeed(seed, purpose)
+			require.False(t, seeds[derived], "Every seed and purpose should derive a different seed")
+			require.NotEqual(t, seed, derived)
+			seeds[derived] = true
+		}
+	}
+
+	require.Equal(t, deriveSeed(7, seedPurposeSplit), deriveSeed(7, seedPurposeSplit), "Deriving should be deterministic")
+}
+
+func TestCheckpointConfigPanics(t *testing.T) {
+	var net network = newTrainingTestNetwork(1)
+
+	assert.Panics(t, func() { newTrainer(&net, trainingConfig{epochs: 1, momentum: 1}) }, "Should panic with momentum of 1")
+	assert.Panics(t, func() { newTrainer(&net, trainingConfig{epochs: 1, momentum: -0.5}) }, "Should panic with negative momentum")
+	assert.Panics(t, func() { newTrainer(&net, trainingConfig{epochs: 1, checkpointPath: "checkpoint"}) }, "Should panic with checkpoint path but no checkpoint rate")
+}
+
+func TestResumedTrainingMatchesUninterruptedTraining(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var config trainingConfig = trainingConfig{
+		epochs:            12,
+		batchSize:         2,
+		learningRateStart: 0.5,
+		learningRateDecay: 0.01,
+		momentum:          0.9,
+		shuffle:           true,
+		seed:              5,
+		checkpointRate:    4,
+	}
+
+	var run func(net *network, config trainingConfig) []float64 = func(net *network, config trainingConfig) []float64 {
+		var history *lossHistoryCallback = &lossHistoryCallback{}
+		var earlyStopping *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{monitor: "val_loss", patience: 100, restoreBestWeights: true})
+		var tr trainer = newTrainer(net, config, earlyStopping, history)
+		tr.setValidationData(inputs, targets)
+
+		require.NoError(t, tr.train(inputs, targets))
+		return history.epochLosses
+	}
+
+	var uninterrupted network = newTrainingTestNetwork(3)
+	config.checkpointPath = filepath.Join(t.TempDir(), "uninterrupted.checkpoint")
+	var uninterruptedLosses []float64 = run(&uninterrupted, config)
+
+	var interrupted network = newTrainingTestNetwork(3)
+	var interruptedConfig trainingConfig = config
+	interruptedConfig.epochs = 8
+	interruptedConfig.checkpointPath = filepath.Join(t.TempDir(), "interrupted.checkpoint")
+	run(&interrupted, interruptedConfig)
+
+	// The resumed network starts from different weights which the checkpoint has to overwrite
+	var resumed network = newTrainingTestNetwork(99)
+	var resumedConfig trainingConfig = interruptedConfig
+	resumedConfig.epochs = 12
+	resumedConfig.resume = true
+	var resumedLosses []float64 = run(&resumed, resumedConfig)
+
+	require.Equal(t, uninterruptedLosses[8:], resumedLosses, "Resumed training should follow the uninterrupted loss trajectory")
+	require.Equal(t, snapshotParameters(&uninterrupted), snapshotParameters(&resumed), "Resumed training should end with the uninterrupted weights")
+
+	checkpoint, err := loadCheckpoint(resumedConfig.checkpointPath)
+	require.NoError(t, err)
+	require.Equal(t, 12, checkpoint.CompletedEpochs, "The last checkpoint should be written after the final epoch")
+	require.Len(t, checkpoint.CallbackStates, 1, "Only the early stopping callback has state to checkpoint")
+}
+
+func TestResumeWithoutCheckpointStartsFresh(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var path string = filepath.Join(t.TempDir(), "missing.checkpoint")
+	var net network = newTrainingTestNetwork(1)
+	var history *lossHistoryCallback = &lossHistoryCallback{}
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 3, learningRateStart: 0.1, checkpointPath: path, checkpointRate: 1, resume: true}, history)
+
+	require.NoError(t, tr.train(inputs, targets))
+	require.Len(t, history.epochLosses, 3, "Training without a checkpoint should run every epoch")
+}
+
+func TestResumeErrors(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var directory string = t.TempDir()
+	var path string = filepath.Join(directory, "training.checkpoint")
+
+	var net network = newTrainingTestNetwork(1)
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 2, learningRateStart: 0.1, checkpointPath: path, checkpointRate: 1})
+	require.NoError(t, tr.train(inputs, targets))
+
+	var l layer = newLayer(3, 4)
+	var otherNet network = newNetwork(&crossentropy{}, &l, &softmax{})
+	var other trainer = newTrainer(&otherNet, trainingConfig{epochs: 4, learningRateStart: 0.1, checkpointPath: path, checkpointRate: 1, resume: true})
+	require.Error(t, other.train(inputs, targets), "Resuming into a different architecture should fail")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("not a checkpoint"), 0644))
+	var corrupt trainer = newTrainer(&net, trainingConfig{epochs: 4, learningRateStart: 0.1, checkpointPath: path, checkpointRate: 1, resume: true})
+	require.Error(t, corrupt.train(inputs, targets), "Resuming from a corrupt checkpoint should fail")
+}
+
+func TestResumeAfterEarlyStoppingStaysStopped(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var path string = filepath.Join(t.TempDir(), "stopped.checkpoint")
+
+	// A learning rate this large makes the loss diverge so the patience runs out almost immediately
+	var config trainingConfig = trainingConfig{epochs: 1000, learningRateStart: 1000, checkpointPath: path, checkpointRate: 100}
+
+	var stopped network = newTrainingTestNetwork(1)
+	var stoppedHistory *lossHistoryCallback = &lossHistoryCallback{}
+	var earlyStopping *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{patience: 3, restoreBestWeights: true})
+	var tr trainer = newTrainer(&stopped, config, earlyStopping, stoppedHistory)
+	require.NoError(t, tr.train(inputs, targets))
+	require.True(t, earlyStopping.stopped, "Early stopping should have stopped training")
+
+	checkpoint, err := loadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, len(stoppedHistory.epochLosses), checkpoint.CompletedEpochs, "The checkpoint should be written when training ends between two checkpoints")
+
+	var resumed network = newTrainingTestNetwork(99)
+	var resumedHistory *lossHistoryCallback = &lossHistoryCallback{}
+	config.resume = true
+	tr = newTrainer(&resumed, config, newEarlyStoppingCallback(earlyStoppingConfig{patience: 3, restoreBestWeights: true}), resumedHistory)
+	require.NoError(t, tr.train(inputs, targets))
+
+	require.Empty(t, resumedHistory.epochLosses, "Resuming a stopped run should not train any further")
+	require.Equal(t, snapshotParameters(&stopped), snapshotParameters(&resumed), "Resuming a stopped run should end with the same restored weights")
+}
@@ -0,0 +1,50 @@
+package main
+
+import "fmt"
+
+// component is a single step of a network that can be propagated forward and backward. predict computes the same
+// output as forward without storing anything for backward, so it is safe to call from many goroutines at once.
+type component interface {
+	forward(input matrix) matrix
+	predict(input matrix) matrix
+	backward(forwardInputDerivatives matrix)
+	getInputDerivatives() matrix
+}
+
+// trainable is implemented by components that own weights and biases. Parameters are flattened into a single
+// vector whose order is fixed per component type and documented on its getParameters; gradients and
+// describeParameter use the same order. Parameter gradients follow the layer convention of being averaged over
+// the batch.
+type trainable interface {
+	parameterCount() int
+	getParameters() vector
+	setParameters(parameters vector)
+	getParameterGradients() vector
+	setParameterGradients(gradients vector)
+	describeParameter(index int) string
+}
+
+// lossFunction is the final step of a network, reducing a batch of outputs and targets to a loss per sample.
+type lossFunction interface {
+	forward(input matrix, targets []int) vector
+	backward()
+	getInputDerivatives() matrix
+	calculateAverageLoss() float64
+}
+
+func describeNeuronParameter(index, inputCount int) string {
+	var neuronIndex int = index / (inputCount + 1)
+	var weightIndex int = index % (inputCount + 1)
+
+	if weightIndex == inputCount {
+		return fmt.Sprintf("neuron[%d].bias", neuronIndex)
+	}
+
+	return fmt.Sprintf("neuron[%d].weights[%d]", neuronIndex, weightIndex)
+}
+
+func checkParameterCount(t trainable, parameters vector) {
+	if len(parameters) != t.parameterCount() {
+		panic(fmt.Sprintf("Parameter count %d does not match expected parameter count %d", len(parameters), t.parameterCount()))
+	}
+}
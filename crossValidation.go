@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// modelFactory builds a new untrained network whose layers draw their initial parameters from random, for example
+// with newDenseLayerWithRandom. The cross-validation runner passes a source seeded per fold.
+type modelFactory func(random *rand.Rand) network
+
+// crossValidationConfig configures crossValidate. Every fold trains a new model with training, whose seed is
+// offset by the fold index, and with parallel the folds train in their own goroutines.
+type crossValidationConfig struct {
+	folds    int
+	seed     int64
+	parallel bool
+	training trainingConfig
+}
+
+// foldResult holds the metrics of one fold: loss and accuracy on the held out samples, and train_loss and
+// train_accuracy on the samples the fold was trained on.
+type foldResult struct {
+	fold      int
+	trainSize int
+	testSize  int
+	metrics   map[string]float64
+}
+
+type crossValidationReport struct {
+	folds []foldResult
+	mean  map[string]float64
+	std   map[string]float64
+}
+
+// stratifiedFolds splits the sample indexes into folds that each hold about the same share of every class. The
+// samples of each class are shuffled and dealt to the folds in turn, continuing from fold to fold across classes
+// so the fold sizes differ by at most one.
+func stratifiedFolds(targets []int, folds int, random *rand.Rand) [][]int {
+	var byClass map[int][]int = map[int][]int{}
+	var classes []int
+	for index, target := range targets {
+		if _, ok := byClass[target]; !ok {
+			classes = append(classes, target)
+		}
+		byClass[target] = append(byClass[target], index)
+	}
+	sort.Ints(classes)
+
+	var result [][]int = make([][]int, folds)
+	var fold int = 0
+	for _, class := range classes {
+		var indexes []int = byClass[class]
+		random.Shuffle(len(indexes), func(i, j int) {
+			indexes[i], indexes[j] = indexes[j], indexes[i]
+		})
+
+		for _, index := range indexes {
+			result[fold] = append(result[fold], index)
+			fold = (fold + 1) % folds
+		}
+	}
+
+	for _, indexes := range result {
+		sort.Ints(indexes)
+	}
+
+	return result
+}
+
+func selectSamples(inputs matrix, targets []int, indexes []int) (matrix, []int) {
+	var selectedInputs matrix = make(matrix, len(indexes))
+	var selectedTargets []int = make([]int, len(indexes))
+	for position, index := range indexes {
+		selectedInputs[position] = inputs[index]
+		selectedTargets[position] = targets[index]
+	}
+
+	return selectedInputs, selectedTargets
+}
+
+// evaluate returns the average loss and the accuracy of n on inputs and targets.
+func evaluate(n *network, inputs matrix, targets []int) (float64, float64) {
+	var loss float64 = n.forward(inputs, targets)
+	return loss, calculateAccuracy(n.lastOutput, targets)
+}
+
+// crossValidate runs stratified K-fold cross-validation: every fold trains a new model from factory on all other
+// folds and evaluates it on the fold. The report is the same for the same seed whether or not folds run in
+// parallel.
+func crossValidate(factory modelFactory, inputs matrix, targets []int, config crossValidationConfig) (crossValidationReport, error) {
+	if config.folds < 2 {
+		panic(fmt.Sprintf("Can not cross-validate with %d folds", config.folds))
+	}
+
+	if len(inputs) < config.folds {
+		panic(fmt.Sprintf("Can not split %d samples into %d folds", len(inputs), config.folds))
+	}
+
+	if len(inputs) != len(targets) {
+		panic(fmt.Sprintf("Targets length %d does not match input batch size %d", len(targets), len(inputs)))
+	}
+
+	if config.training.checkpointPath != "" {
+		panic("Can not checkpoint during cross-validation, all folds would write the same checkpoint")
+	}
+
+	var folds [][]int = stratifiedFolds(targets, config.folds, rand.New(newSplitMix64Source(deriveSeed(config.seed, seedPurposeSplit))))
+
+	var models []network = make([]network, config.folds)
+	for fold := range models {
+		models[fold] = factory(rand.New(newSplitMix64Source(deriveSeed(config.seed+int64(fold), seedPurposeInitialization))))
+	}
+
+	var results []foldResult = make([]foldResult, config.folds)
+	var errs []error = make([]error, config.folds)
+
+	var runFold func(fold int) = func(fold int) {
+		var trainIndexes []int
+		for otherFold, indexes := range folds {
+			if otherFold != fold {
+				trainIndexes = append(trainIndexes, indexes...)
+			}
+		}
+		sort.Ints(trainIndexes)
+
+		trainInputs, trainTargets := selectSamples(inputs, targets, trainIndexes)
+		testInputs, testTargets := selectSamples(inputs, targets, folds[fold])
+
+		var training trainingConfig = config.training
+		training.seed += int64(fold)
+		var foldTrainer trainer = newTrainer(&models[fold], training)
+		if err := foldTrainer.train(trainInputs, trainTargets); err != nil {
+			errs[fold] = fmt.Errorf("fold %d: %w", fold+1, err)
+			return
+		}
+
+		var result foldResult = foldResult{fold: fold + 1, trainSize: len(trainInputs), testSize: len(testInputs), metrics: map[string]float64{}}
+		result.metrics["train_loss"], result.metrics["train_accuracy"] = evaluate(&models[fold], trainInputs, trainTargets)
+		result.metrics["loss"], result.metrics["accuracy"] = evaluate(&models[fold], testInputs, testTargets)
+		results[fold] = result
+	}
+
+	if config.parallel {
+		var waitGroup sync.WaitGroup
+		for fold := range folds {
+			waitGroup.Add(1)
+			go func(fold int) {
+				defer waitGroup.Done()
+				runFold(fold)
+			}(fold)
+		}
+		waitGroup.Wait()
+	} else {
+		for fold := range folds {
+			runFold(fold)
+			if errs[fold] != nil {
+				break
+			}
+		}
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return crossValidationReport{}, err
+		}
+	}
+
+	return newCrossValidationReport(results), nil
+}
+
+// newCrossValidationReport summarizes fold results with the mean and population standard deviation of every metric.
+func newCrossValidationReport(results []foldResult) crossValidationReport {
+	var report crossValidationReport = crossValidationReport{folds: results, mean: map[string]float64{}, std: map[string]float64{}}
+
+	for name := range results[0].metrics {
+		var values vector = make(vector, len(results))
+		for index, result := range results {
+			values[index] = result.metrics[name]
+		}
+
+		var mean float64 = vectorSum(values) / float64(len(values))
+		var squaredDeviations float64 = 0
+		for _, value := range values {
+			squaredDeviations += (value - mean) * (value - mean)
+		}
+
+		report.mean[name] = mean
+		report.std[name] = math.Sqrt(squaredDeviations / float64(len(values)))
+	}
+
+	return report
+}
+
+func (r crossValidationReport) metricNames() []string {
+	var names []string
+	for name := range r.mean {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// String formats the report as a table with a row per fold and a final mean±std row.
+func (r crossValidationReport) String() string {
+	var names []string = r.metricNames()
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%-6s %6s %6s", "fold", "train", "test")
+	for _, name := range names {
+		fmt.Fprintf(&builder, " %17s", name)
+	}
+	builder.WriteString("\n")
+
+	for _, result := range r.folds {
+		fmt.Fprintf(&builder, "%-6d %6d %6d", result.fold, result.trainSize, result.testSize)
+		for _, name := range names {
+			fmt.Fprintf(&builder, " %17.4f", result.metrics[name])
+		}
+		builder.WriteString("\n")
+	}
+
+	fmt.Fprintf(&builder, "%-20s", "mean±std")
+	for _, name := range names {
+		fmt.Fprintf(&builder, " %17s", fmt.Sprintf("%.4f±%.4f", r.mean[name], r.std[name]))
+	}
+	builder.WriteString("\n")
+
+	return builder.String()
+}
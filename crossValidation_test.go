@@ -0,0 +1,123 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCrossValidationTestNetwork(random *rand.Rand) network {
+	return newDenseClassifier(2, 8, 3, random)
+}
+
+func crossValidationTestConfig(parallel bool) crossValidationConfig {
+	return crossValidationConfig{
+		folds:    4,
+		seed:     5,
+		parallel: parallel,
+		training: trainingConfig{epochs: 30, batchSize: 8, learningRateStart: 0.05, momentum: 0.9, shuffle: true, seed: 1},
+	}
+}
+
+func TestStratifiedFolds(t *testing.T) {
+	var targets []int = []int{0, 0, 0, 0, 0, 0, 1, 1, 1, 2, 2, 2, 2}
+	var folds [][]int = stratifiedFolds(targets, 3, rand.New(newSplitMix64Source(1)))
+
+	var seen map[int]bool = map[int]bool{}
+	for _, fold := range folds {
+		assert.True(t, len(fold) == 4 || len(fold) == 5, "Fold sizes should differ by at most one")
+
+		var classCounts map[int]int = map[int]int{}
+		for _, index := range fold {
+			require.False(t, seen[index], "Every sample should be in exactly one fold")
+			seen[index] = true
+			classCounts[targets[index]]++
+		}
+
+		assert.Equal(t, 2, classCounts[0], "Every fold should hold its share of every class")
+		assert.Equal(t, 1, classCounts[1])
+	}
+
+	assert.Len(t, seen, len(targets))
+}
+
+func TestCrossValidate(t *testing.T) {
+	inputs, targets := generateBlobs(20, 3, 2, 0.5, 3)
+	inputs = newFeatureScaler(scalerKindStandard).fitTransform(inputs)
+
+	report, err := crossValidate(newCrossValidationTestNetwork, inputs, targets, crossValidationTestConfig(false))
+	require.NoError(t, err)
+
+	require.Len(t, report.folds, 4)
+	for index, result := range report.folds {
+		assert.Equal(t, index+1, result.fold)
+		assert.Equal(t, 45, result.trainSize)
+		assert.Equal(t, 15, result.testSize)
+	}
+
+	assert.Greater(t, report.mean["accuracy"], 0.9, "Separable blobs should be classified on held out folds")
+	assert.Equal(t, []string{"accuracy", "loss", "train_accuracy", "train_loss"}, report.metricNames())
+
+	var accuracies vector
+	for _, result := range report.folds {
+		accuracies = append(accuracies, result.metrics["accuracy"])
+	}
+	assert.InDelta(t, vectorSum(accuracies)/4, report.mean["accuracy"], 1e-12)
+	assert.False(t, math.IsNaN(report.std["loss"]))
+
+	var table string = report.String()
+	assert.Equal(t, 6, strings.Count(table, "\n"), "The table should have a header, a row per fold and a summary row")
+	assert.Contains(t, table, "mean±std")
+}
+
+func TestCrossValidateParallelIsDeterministic(t *testing.T) {
+	inputs, targets := generateSpiral(15, 3, 0.2, 2)
+
+	sequential, err := crossValidate(newCrossValidationTestNetwork, inputs, targets, crossValidationTestConfig(false))
+	require.NoError(t, err)
+	parallel, err := crossValidate(newCrossValidationTestNetwork, inputs, targets, crossValidationTestConfig(true))
+	require.NoError(t, err)
+
+	assert.Equal(t, sequential, parallel, "Running folds in parallel should not change the results")
+}
+
+func TestCrossValidateLeavesGlobalRandomAlone(t *testing.T) {
+	inputs, targets := generateBlobs(5, 3, 2, 0.5, 3)
+
+	rand.Seed(21)
+	var expected float64 = rand.Float64()
+	rand.Seed(21)
+	_, err := crossValidate(newCrossValidationTestNetwork, inputs, targets, crossValidationTestConfig(false))
+	require.NoError(t, err)
+	assert.Equal(t, expected, rand.Float64(), "Cross-validation should not seed or draw from the global random source")
+}
+
+func TestCrossValidateErrors(t *testing.T) {
+	inputs, targets := generateBlobs(5, 3, 2, 0.5, 3)
+	var config crossValidationConfig = crossValidationTestConfig(true)
+
+	var invalid crossValidationConfig = config
+	invalid.training.checkNumericHealth = true
+	invalid.training.learningRateStart = math.Inf(1)
+	_, err := crossValidate(newCrossValidationTestNetwork, inputs, targets, invalid)
+	assert.Error(t, err, "Training errors of a fold should be returned")
+
+	invalid = config
+	invalid.folds = 1
+	assert.Panics(t, func() { crossValidate(newCrossValidationTestNetwork, inputs, targets, invalid) }, "Should panic with 1 fold")
+
+	invalid = config
+	invalid.folds = 16
+	assert.Panics(t, func() { crossValidate(newCrossValidationTestNetwork, inputs, targets, invalid) }, "Should panic with more folds than samples")
+
+	invalid = config
+	invalid.training.checkpointPath = "checkpoint.gob"
+	invalid.training.checkpointRate = 1
+	assert.Panics(t, func() { crossValidate(newCrossValidationTestNetwork, inputs, targets, invalid) }, "Should panic with a checkpoint path")
+
+	assert.Panics(t, func() { crossValidate(newCrossValidationTestNetwork, inputs, targets[1:], config) }, "Should panic with mismatched targets")
+}
@@ -2,94 +2,137 @@ package main
 
 import (
 	"encoding/csv"
-	"log"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"strconv"
+	"strings"
 )
 
 const irisSmallCsvPath string = `C:\Users\THPC\Main\Development\Go\lnet\data\iris_small.csv`
 
-func extractIrisSmall() (matrix, []int) {
+// irisSmallLabels names the targets of extractIrisSmall in the order of the label columns.
+var irisSmallLabels []string = []string{"setosa", "virginica", "versicolor"}
+
+func extractIrisSmall() (matrix, []int, error) {
 	var file *os.File
 	var err error
 
 	file, err = os.Open(irisSmallCsvPath)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
+	}
+	defer file.Close()
+
+	return readIrisSmall(file)
+}
+
+// extractIrisSmallWithMissing is extractIrisSmall with missing feature values handled by handler.
+func extractIrisSmallWithMissing(handler *missingValueHandler) (matrix, []int, missingValueReport, error) {
+	file, err := os.Open(irisSmallCsvPath)
+	if err != nil {
+		return nil, nil, missingValueReport{}, err
 	}
 	defer file.Close()
 
-	var reader *csv.Reader = csv.NewReader(file)
+	return readIrisSmallWithMissing(file, handler)
+}
+
+// readIrisSmall parses the small iris CSV format: a header row, then four feature columns followed by three
+// one-hot label columns.
+func readIrisSmall(source io.Reader) (matrix, []int, error) {
+	inputs, targets, _, err := readIrisSmallWithMissing(source, nil)
+	return inputs, targets, err
+}
+
+// readIrisSmallWithMissing is readIrisSmall with missing feature values handled by handler, which can drop rows and
+// append indicator columns to the inputs. An unfitted handler is fitted on this file; a fitted one is reused as is,
+// so validation and test files are imputed with the statistics of the training file. A nil handler fails on
+// missing values. Missing label values are always an error.
+func readIrisSmallWithMissing(source io.Reader, handler *missingValueHandler) (matrix, []int, missingValueReport, error) {
+	var reader *csv.Reader = csv.NewReader(source)
 	var rawCsvData [][]string
 
-	rawCsvData, err = reader.ReadAll()
+	rawCsvData, err := reader.ReadAll()
 
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, missingValueReport{}, err
 	}
 
 	var rawCsvDataLen int = len(rawCsvData)
 	if rawCsvDataLen <= 1 {
-		log.Fatal("Small Iris CSV data file contains no rows or only the header row. Can not extract data")
+		return nil, nil, missingValueReport{}, errors.New("Small Iris CSV data file contains no rows or only the header row. Can not extract data")
 	}
 
-	var inputs matrix = make(matrix, rawCsvDataLen - 1)
-	var targets []int = make([]int, rawCsvDataLen - 1)
-
-	for recrodIndex, record := range rawCsvData {
-		if recrodIndex == 0 {
-			continue
-		}
+	var featureRecords [][]string = make([][]string, rawCsvDataLen-1)
+	for recrodIndex, record := range rawCsvData[1:] {
 		var recrodLen int = len(record)
 
 		if recrodLen != 7 {
-			log.Fatal("Small Iris CSV data file contains rows with less or more than 7 values. All rows must have exactly 7 values")
+			return nil, nil, missingValueReport{}, newShapeMismatchError(7, recrodLen, "Small Iris CSV data file contains rows with less or more than 7 values. All rows must have exactly 7 values")
 		}
 
-		var inputSample vector = make(vector, recrodLen - 3)
-		var sampleTarget int = -1
+		featureRecords[recrodIndex] = record[:4]
+	}
+
+	if handler == nil {
+		handler = newMissingValueHandler(missingValuePolicy{strategy: missingFail})
+	}
+
+	if !handler.fitted {
+		if err = handler.fit(featureRecords); err != nil {
+			return nil, nil, missingValueReport{}, err
+		}
+	}
+
+	featureRecords, kept, report, err := handler.apply(featureRecords)
+	if err != nil {
+		return nil, nil, report, err
+	}
+
+	var inputs matrix = make(matrix, len(kept))
+	var targets []int = make([]int, len(kept))
 
-		for recrodValueIndex, recrodValue := range record {
-			if recrodValueIndex == 0 || recrodValueIndex == 1 || recrodValueIndex == 2 || recrodValueIndex == 3 {
-				floatValue, err := strconv.ParseFloat(recrodValue, 64)
-				if err != nil {
-					log.Fatal(err)
-				}
+	for sampleIndex, keptIndex := range kept {
+		var recrodIndex int = keptIndex + 1
+		var inputSample vector = make(vector, len(featureRecords[sampleIndex]))
 
-				inputSample[recrodValueIndex] = floatValue
-				continue
+		for recrodValueIndex, recrodValue := range featureRecords[sampleIndex] {
+			floatValue, err := strconv.ParseFloat(strings.TrimSpace(recrodValue), 64)
+			if err != nil {
+				return nil, nil, report, fmt.Errorf("row %d: %w", recrodIndex, err)
 			}
 
+			inputSample[recrodValueIndex] = floatValue
+		}
+
+		var sampleTarget int = -1
+
+		for labelIndex, recrodValue := range rawCsvData[recrodIndex][4:] {
 			floatValue, err := strconv.ParseFloat(recrodValue, 64)
 			var intValue int = int(floatValue)
 
 			if err != nil {
-				log.Fatal(err)
+				return nil, nil, report, fmt.Errorf("row %d: %w", recrodIndex, err)
 			}
 
 			if intValue != 1 && intValue != 0 {
-				log.Fatal("All row values from indexes 4 through 6 must be integers 0 or 1")
+				return nil, nil, report, fmt.Errorf("row %d: All row values from indexes 4 through 6 must be integers 0 or 1", recrodIndex)
 			}
 
-			if recrodValueIndex == 4 && intValue == 1 {
-				sampleTarget = 0
-			}
-			if recrodValueIndex == 5 && intValue == 1 {
-				sampleTarget = 1
-			}
-			if recrodValueIndex == 6 && intValue == 1 {
-				sampleTarget = 2
+			if intValue == 1 {
+				sampleTarget = labelIndex
 			}
 		}
 
 		if sampleTarget == -1 {
-			log.Fatal("A row does not contain a value of 1 in any of the label cloumns")
+			return nil, nil, report, fmt.Errorf("row %d: A row does not contain a value of 1 in any of the label cloumns", recrodIndex)
 		}
 
-		inputs[recrodIndex - 1] = inputSample
-		targets[recrodIndex - 1] = sampleTarget
+		inputs[sampleIndex] = inputSample
+		targets[sampleIndex] = sampleTarget
 	}
 
-	return inputs, targets
-
+	return inputs, targets, report, nil
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const irisSmallTestHeader string = "sepal_length,sepal_width,petal_length,petal_width,setosa,virginica,versicolor\n"
+
+func TestReadIrisSmall(t *testing.T) {
+	inputs, targets, err := readIrisSmall(strings.NewReader(irisSmallTestHeader +
+		"0.1,0.2,0.3,0.4,1,0,0\n" +
+		"0.5,0.6,0.7,0.8,0,0,1\n"))
+
+	require.NoError(t, err)
+	assert.Equal(t, matrix{{0.1, 0.2, 0.3, 0.4}, {0.5, 0.6, 0.7, 0.8}}, inputs)
+	assert.Equal(t, []int{0, 2}, targets)
+}
+
+func TestReadIrisSmallErrors(t *testing.T) {
+	var files map[string]string = map[string]string{
+		"header only":   irisSmallTestHeader,
+		"bad feature":   irisSmallTestHeader + "a,0.2,0.3,0.4,1,0,0\n",
+		"bad label":     irisSmallTestHeader + "0.1,0.2,0.3,0.4,2,0,0\n",
+		"missing label": irisSmallTestHeader + "0.1,0.2,0.3,0.4,0,0,0\n",
+	}
+
+	for name, contents := range files {
+		_, _, err := readIrisSmall(strings.NewReader(contents))
+		assert.Error(t, err, name)
+	}
+
+	var shapeErr *shapeMismatchError
+	_, _, err := readIrisSmall(strings.NewReader("a,b,c\n1,2,3\n"))
+	require.True(t, errors.As(err, &shapeErr), "Rows without 7 values should fail with a shapeMismatchError")
+	assert.Equal(t, 3, shapeErr.actual)
+}
+
+func TestReadIrisSmallWithMissing(t *testing.T) {
+	var contents string = irisSmallTestHeader +
+		"0.1,0.2,0.3,0.4,1,0,0\n" +
+		"NA,0.6,0.7,0.8,0,0,1\n" +
+		"0.3,,0.5,0.6,0,1,0\n"
+
+	_, _, err := readIrisSmall(strings.NewReader(contents))
+	assert.Error(t, err, "Missing values should be an error without a handler")
+
+	var handler *missingValueHandler = newMissingValueHandler(missingValuePolicy{strategy: missingMean})
+	handler.setPolicy(1, missingValuePolicy{strategy: missingDropRow})
+
+	inputs, targets, report, err := readIrisSmallWithMissing(strings.NewReader(contents), handler)
+	require.NoError(t, err)
+	assert.Equal(t, matrix{{0.1, 0.2, 0.3, 0.4}, {0.2, 0.6, 0.7, 0.8}}, inputs)
+	assert.Equal(t, []int{0, 2}, targets, "Targets of dropped rows should be dropped too")
+	assert.Equal(t, 2, report.missingCells)
+	assert.Equal(t, 1, report.droppedRows)
+}
+
+func TestReadIrisSmallWithMissingReusesFittedHandler(t *testing.T) {
+	var training string = irisSmallTestHeader +
+		"0.1,0.2,0.3,0.4,1,0,0\n" +
+		"0.3,0.6,0.7,0.8,0,0,1\n"
+	var test string = irisSmallTestHeader +
+		"NA,0.2,0.3,0.4,1,0,0\n" +
+		"0.9,0.6,0.7,0.8,0,1,0\n"
+
+	var handler *missingValueHandler = newMissingValueHandler(missingValuePolicy{strategy: missingMean})
+	_, _, _, err := readIrisSmallWithMissing(strings.NewReader(training), handler)
+	require.NoError(t, err)
+
+	inputs, _, _, err := readIrisSmallWithMissing(strings.NewReader(test), handler)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.2, inputs[0][0], 1e-12, "The test file should be imputed with the training mean, not its own")
+}
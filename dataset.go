@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// defaultShuffleBufferSize is the shuffle buffer of streamed training when shuffle is on and no size is configured.
+const defaultShuffleBufferSize int = 1024
+
+// sample is one input row and its target class.
+type sample struct {
+	input  vector
+	target int
+}
+
+// dataset streams samples from a source that may be larger than memory. next returns io.EOF once every sample
+// has been read, and reset rewinds to the first sample for the next epoch.
+type dataset interface {
+	next() (sample, error)
+	reset() error
+	close() error
+}
+
+// batchStream reads batches from a dataset. With a shuffle buffer it holds up to shuffleBufferSize samples and
+// emits a random one of them for every sample it reads, which shuffles locally without loading the whole
+// dataset. Memory use is bounded by the shuffle buffer and the batch size.
+type batchStream struct {
+	source            dataset
+	batchSize         int
+	shuffleBufferSize int
+	random            *rand.Rand
+	buffer            []sample
+	exhausted         bool
+}
+
+// newBatchStream reads batches of batchSize samples from source. A shuffleBufferSize of 0 keeps the source order.
+func newBatchStream(source dataset, batchSize, shuffleBufferSize int, random *rand.Rand) *batchStream {
+	if batchSize <= 0 {
+		panic(fmt.Sprintf("Can not stream batches of size %d", batchSize))
+	}
+
+	if shuffleBufferSize < 0 {
+		panic(fmt.Sprintf("Can not shuffle with buffer size %d", shuffleBufferSize))
+	}
+
+	if shuffleBufferSize > 0 && random == nil {
+		panic("Can not shuffle without a random source")
+	}
+
+	return &batchStream{source: source, batchSize: batchSize, shuffleBufferSize: shuffleBufferSize, random: random}
+}
+
+func (b *batchStream) nextSample() (sample, error) {
+	if b.shuffleBufferSize == 0 {
+		return b.source.next()
+	}
+
+	for !b.exhausted && len(b.buffer) < b.shuffleBufferSize {
+		next, err := b.source.next()
+		if err == io.EOF {
+			b.exhausted = true
+			break
+		}
+		if err != nil {
+			return sample{}, err
+		}
+
+		b.buffer = append(b.buffer, next)
+	}
+
+	if len(b.buffer) == 0 {
+		return sample{}, io.EOF
+	}
+
+	var index int = b.random.Intn(len(b.buffer))
+	var picked sample = b.buffer[index]
+	var last int = len(b.buffer) - 1
+	b.buffer[index] = b.buffer[last]
+	b.buffer = b.buffer[:last]
+
+	return picked, nil
+}
+
+// nextBatch returns the next batch, which is smaller than batchSize only at the end of the dataset, or io.EOF
+// once every sample has been returned.
+func (b *batchStream) nextBatch() (matrix, []int, error) {
+	var inputs matrix = make(matrix, 0, b.batchSize)
+	var targets []int = make([]int, 0, b.batchSize)
+
+	for len(inputs) < b.batchSize {
+		next, err := b.nextSample()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		inputs = append(inputs, next.input)
+		targets = append(targets, next.target)
+	}
+
+	if len(inputs) == 0 {
+		return nil, nil, io.EOF
+	}
+
+	return inputs, targets, nil
+}
+
+// csvDataset streams a CSV file whose targetColumn holds the target and whose other columns are numeric inputs.
+// Targets are parsed as class indexes, or mapped by labels if it is not nil.
+type csvDataset struct {
+	path         string
+	targetColumn int
+	hasHeader    bool
+	labels       *labelEncoder
+	file         *os.File
+	reader       *csv.Reader
+	row          int
+}
+
+func newCSVDataset(path string, targetColumn int, hasHeader bool, labels *labelEncoder) (*csvDataset, error) {
+	if targetColumn < 0 {
+		panic(fmt.Sprintf("Can not use column %d as target", targetColumn))
+	}
+
+	var d *csvDataset = &csvDataset{path: path, targetColumn: targetColumn, hasHeader: hasHeader, labels: labels}
+	if err := d.reset(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *csvDataset) reset() error {
+	if d.file != nil {
+		d.file.Close()
+	}
+
+	file, err := os.Open(d.path)
+	if err != nil {
+		return err
+	}
+
+	d.file = file
+	d.reader = csv.NewReader(bufio.NewReader(file))
+	d.reader.ReuseRecord = true
+	d.row = 0
+
+	if d.hasHeader {
+		if _, err = d.reader.Read(); err != nil && err != io.EOF {
+			return err
+		}
+		d.row++
+	}
+
+	return nil
+}
+
+func (d *csvDataset) next() (sample, error) {
+	record, err := d.reader.Read()
+	if err != nil {
+		return sample{}, err
+	}
+	d.row++
+
+	if d.targetColumn >= len(record) {
+		return sample{}, newShapeMismatchError(d.targetColumn+1, len(record), "row %d has %d columns, too few for target column %d", d.row, len(record), d.targetColumn)
+	}
+
+	var input vector = make(vector, 0, len(record)-1)
+	for columnIndex, value := range record {
+		if columnIndex == d.targetColumn {
+			continue
+		}
+
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return sample{}, fmt.Errorf("row %d: %w", d.row, err)
+		}
+
+		input = append(input, parsed)
+	}
+
+	var target int
+	var targetValue string = strings.TrimSpace(record[d.targetColumn])
+	if d.labels != nil {
+		targets, err := d.labels.transform([]string{targetValue})
+		if err != nil {
+			return sample{}, fmt.Errorf("row %d: %w", d.row, err)
+		}
+
+		target = targets[0]
+	} else if target, err = strconv.Atoi(targetValue); err != nil {
+		return sample{}, fmt.Errorf("row %d: %w", d.row, err)
+	}
+
+	return sample{input: input, target: target}, nil
+}
+
+func (d *csvDataset) close() error {
+	if d.file == nil {
+		return nil
+	}
+
+	var err error = d.file.Close()
+	d.file = nil
+	return err
+}
+
+// jsonLinesRecord is one line of a JSON lines dataset.
+type jsonLinesRecord struct {
+	Input  vector `json:"input"`
+	Target *int   `json:"target"`
+}
+
+// jsonLinesDataset streams a file with one {"input": [...], "target": 0} object per line.
+type jsonLinesDataset struct {
+	path    string
+	file    *os.File
+	decoder *json.Decoder
+	line    int
+}
+
+func newJSONLinesDataset(path string) (*jsonLinesDataset, error) {
+	var d *jsonLinesDataset = &jsonLinesDataset{path: path}
+	if err := d.reset(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *jsonLinesDataset) reset() error {
+	if d.file != nil {
+		d.file.Close()
+	}
+
+	file, err := os.Open(d.path)
+	if err != nil {
+		return err
+	}
+
+	d.file = file
+	d.decoder = json.NewDecoder(bufio.NewReader(file))
+	d.decoder.DisallowUnknownFields()
+	d.line = 0
+	return nil
+}
+
+func (d *jsonLinesDataset) next() (sample, error) {
+	var record jsonLinesRecord
+	if err := d.decoder.Decode(&record); err != nil {
+		if err == io.EOF {
+			return sample{}, err
+		}
+
+		return sample{}, fmt.Errorf("record %d: %w", d.line+1, err)
+	}
+	d.line++
+
+	if len(record.Input) == 0 || record.Target == nil {
+		return sample{}, fmt.Errorf("record %d: input and target are required", d.line)
+	}
+
+	return sample{input: record.Input, target: *record.Target}, nil
+}
+
+func (d *jsonLinesDataset) close() error {
+	if d.file == nil {
+		return nil
+	}
+
+	var err error = d.file.Close()
+	d.file = nil
+	return err
+}
+
+// binaryDataset streams fixed size records of inputCount little-endian float64 inputs followed by the target as
+// a float64, as written by writeBinaryRecords.
+type binaryDataset struct {
+	path       string
+	inputCount int
+	file       *os.File
+	reader     *bufio.Reader
+	buffer     []byte
+	record     int
+}
+
+func newBinaryDataset(path string, inputCount int) (*binaryDataset, error) {
+	if inputCount <= 0 {
+		return nil, newInvalidDimensionError("inputCount", inputCount, "Can not read binary records with %d inputs", inputCount)
+	}
+
+	var d *binaryDataset = &binaryDataset{path: path, inputCount: inputCount, buffer: make([]byte, 8*(inputCount+1))}
+	if err := d.reset(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (d *binaryDataset) reset() error {
+	if d.file != nil {
+		d.file.Close()
+	}
+
+	file, err := os.Open(d.path)
+	if err != nil {
+		return err
+	}
+
+	d.file = file
+	d.reader = bufio.NewReader(file)
+	d.record = 0
+	return nil
+}
+
+func (d *binaryDataset) next() (sample, error) {
+	if _, err := io.ReadFull(d.reader, d.buffer); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return sample{}, fmt.Errorf("record %d is truncated: %w", d.record+1, err)
+		}
+
+		return sample{}, err
+	}
+	d.record++
+
+	var input vector = make(vector, d.inputCount)
+	for index := range input {
+		input[index] = math.Float64frombits(binary.LittleEndian.Uint64(d.buffer[8*index:]))
+	}
+
+	var target float64 = math.Float64frombits(binary.LittleEndian.Uint64(d.buffer[8*d.inputCount:]))
+	if target != math.Trunc(target) || target < 0 {
+		return sample{}, fmt.Errorf("record %d: target %v is not a class index", d.record, target)
+	}
+
+	return sample{input: input, target: int(target)}, nil
+}
+
+func (d *binaryDataset) close() error {
+	if d.file == nil {
+		return nil
+	}
+
+	var err error = d.file.Close()
+	d.file = nil
+	return err
+}
+
+// writeBinaryRecords writes inputs and targets in the format binaryDataset reads.
+func writeBinaryRecords(destination io.Writer, inputs matrix, targets []int) error {
+	if len(inputs) != len(targets) {
+		return newShapeMismatchError(len(inputs), len(targets), "Targets length %d does not match input count %d", len(targets), len(inputs))
+	}
+
+	var writer *bufio.Writer = bufio.NewWriter(destination)
+	var buffer [8]byte
+
+	for index, input := range inputs {
+		if len(input) != len(inputs[0]) {
+			return newShapeMismatchError(len(inputs[0]), len(input), "Input %d has %d values but the first has %d", index, len(input), len(inputs[0]))
+		}
+
+		for _, value := range append(input[:len(input):len(input)], float64(targets[index])) {
+			binary.LittleEndian.PutUint64(buffer[:], math.Float64bits(value))
+			if _, err := writer.Write(buffer[:]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return writer.Flush()
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sliceDataset is an in-memory dataset for tests.
+type sliceDataset struct {
+	samples  []sample
+	position int
+	resets   int
+}
+
+func newSliceDataset(inputs matrix, targets []int) *sliceDataset {
+	var d *sliceDataset = &sliceDataset{}
+	for index, input := range inputs {
+		d.samples = append(d.samples, sample{input: input, target: targets[index]})
+	}
+
+	return d
+}
+
+func (d *sliceDataset) next() (sample, error) {
+	if d.position == len(d.samples) {
+		return sample{}, io.EOF
+	}
+
+	d.position++
+	return d.samples[d.position-1], nil
+}
+
+func (d *sliceDataset) reset() error {
+	d.position = 0
+	d.resets++
+	return nil
+}
+
+func (d *sliceDataset) close() error {
+	return nil
+}
+
+// readAllBatches reads every batch of stream and returns the targets of each batch.
+func readAllBatches(t *testing.T, stream *batchStream) [][]int {
+	var batches [][]int
+	for {
+		inputs, targets, err := stream.nextBatch()
+		if err == io.EOF {
+			return batches
+		}
+
+		require.NoError(t, err)
+		require.Len(t, inputs, len(targets))
+		batches = append(batches, targets)
+	}
+}
+
+func countingDataset(count int) *sliceDataset {
+	var inputs matrix = make(matrix, count)
+	var targets []int = make([]int, count)
+	for index := range inputs {
+		inputs[index] = vector{float64(index)}
+		targets[index] = index
+	}
+
+	return newSliceDataset(inputs, targets)
+}
+
+func TestBatchStreamInOrder(t *testing.T) {
+	var batches [][]int = readAllBatches(t, newBatchStream(countingDataset(7), 3, 0, nil))
+
+	assert.Equal(t, [][]int{{0, 1, 2}, {3, 4, 5}, {6}}, batches)
+}
+
+func TestBatchStreamShuffleBuffer(t *testing.T) {
+	var first [][]int = readAllBatches(t, newBatchStream(countingDataset(100), 8, 16, rand.New(newSplitMix64Source(1))))
+	var second [][]int = readAllBatches(t, newBatchStream(countingDataset(100), 8, 16, rand.New(newSplitMix64Source(1))))
+	assert.Equal(t, first, second, "Shuffling should be deterministic for a seed")
+
+	var all []int
+	for _, batch := range first {
+		all = append(all, batch...)
+	}
+
+	assert.NotEqual(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, all[:10], "Samples should be shuffled")
+	sort.Ints(all)
+	for index, target := range all {
+		require.Equal(t, index, target, "Every sample should be returned exactly once")
+	}
+
+	assert.Less(t, first[0][0], 16, "The first sample can only come from the first buffer")
+}
+
+func TestBatchStreamPanics(t *testing.T) {
+	assert.Panics(t, func() { newBatchStream(countingDataset(1), 0, 0, nil) }, "Should panic with batch size 0")
+	assert.Panics(t, func() { newBatchStream(countingDataset(1), 1, -1, nil) }, "Should panic with negative buffer size")
+	assert.Panics(t, func() { newBatchStream(countingDataset(1), 1, 4, nil) }, "Should panic when shuffling without random source")
+}
+
+// readAllSamples reads source twice through a reset and checks both passes return the same samples.
+func readAllSamples(t *testing.T, source dataset) []sample {
+	var passes [2][]sample
+	for pass := range passes {
+		for {
+			next, err := source.next()
+			if err == io.EOF {
+				break
+			}
+
+			require.NoError(t, err)
+			passes[pass] = append(passes[pass], next)
+		}
+
+		require.NoError(t, source.reset())
+	}
+
+	require.Equal(t, passes[0], passes[1], "Reset should rewind to the first sample")
+	require.NoError(t, source.close())
+	return passes[0]
+}
+
+func writeTestFile(t *testing.T, name, contents string) string {
+	var path string = filepath.Join(t.TempDir(), name)
+	require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	return path
+}
+
+func TestCSVDataset(t *testing.T) {
+	var path string = writeTestFile(t, "data.csv", "a,class,b\n0.5,1,2\n-1,0, 3\n")
+
+	source, err := newCSVDataset(path, 1, true, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []sample{{input: vector{0.5, 2}, target: 1}, {input: vector{-1, 3}, target: 0}}, readAllSamples(t, source))
+
+	path = writeTestFile(t, "labelled.csv", "0.5,2,yes\n-1,3,no\n")
+	source, err = newCSVDataset(path, 2, false, newLabelEncoder("no", "yes"))
+	require.NoError(t, err)
+	assert.Equal(t, []sample{{input: vector{0.5, 2}, target: 1}, {input: vector{-1, 3}, target: 0}}, readAllSamples(t, source))
+}
+
+func TestJSONLinesDataset(t *testing.T) {
+	var path string = writeTestFile(t, "data.jsonl", `{"input": [0.5, 2], "target": 1}`+"\n"+`{"input": [-1, 3], "target": 0}`+"\n")
+
+	source, err := newJSONLinesDataset(path)
+	require.NoError(t, err)
+	assert.Equal(t, []sample{{input: vector{0.5, 2}, target: 1}, {input: vector{-1, 3}, target: 0}}, readAllSamples(t, source))
+}
+
+func TestBinaryDataset(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, writeBinaryRecords(&buffer, matrix{{0.5, 2}, {-1, 3}}, []int{1, 0}))
+	assert.Equal(t, 2*3*8, buffer.Len())
+	var path string = writeTestFile(t, "data.bin", buffer.String())
+
+	source, err := newBinaryDataset(path, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []sample{{input: vector{0.5, 2}, target: 1}, {input: vector{-1, 3}, target: 0}}, readAllSamples(t, source))
+
+	assert.Error(t, writeBinaryRecords(&buffer, matrix{{1}}, []int{}), "Targets have to match inputs")
+	assert.Error(t, writeBinaryRecords(&buffer, matrix{{1}, {1, 2}}, []int{0, 0}), "Inputs have to have the same length")
+}
+
+func TestDatasetErrors(t *testing.T) {
+	var datasets map[string]func() (dataset, error) = map[string]func() (dataset, error){
+		"csv bad input": func() (dataset, error) {
+			return newCSVDataset(writeTestFile(t, "data.csv", "x,1\n"), 1, false, nil)
+		},
+		"csv bad target": func() (dataset, error) {
+			return newCSVDataset(writeTestFile(t, "data.csv", "1,one\n"), 1, false, nil)
+		},
+		"csv short row": func() (dataset, error) {
+			return newCSVDataset(writeTestFile(t, "data.csv", "1\n"), 1, false, nil)
+		},
+		"csv unknown label": func() (dataset, error) {
+			return newCSVDataset(writeTestFile(t, "data.csv", "1,maybe\n"), 1, false, newLabelEncoder("no", "yes"))
+		},
+		"json syntax": func() (dataset, error) {
+			return newJSONLinesDataset(writeTestFile(t, "data.jsonl", "{\n"))
+		},
+		"json missing target": func() (dataset, error) {
+			return newJSONLinesDataset(writeTestFile(t, "data.jsonl", `{"input": [1]}`))
+		},
+		"binary truncated": func() (dataset, error) {
+			return newBinaryDataset(writeTestFile(t, "data.bin", strings.Repeat("\x00", 12)), 1)
+		},
+		"binary fractional target": func() (dataset, error) {
+			var buffer bytes.Buffer
+			require.NoError(t, writeBinaryRecords(&buffer, matrix{{1}}, []int{0}))
+			var data []byte = buffer.Bytes()
+			data[15] = 0x3f
+			return newBinaryDataset(writeTestFile(t, "data.bin", string(data)), 1)
+		},
+	}
+
+	for name, open := range datasets {
+		source, err := open()
+		require.NoError(t, err, name)
+
+		_, err = source.next()
+		assert.Error(t, err, name)
+		assert.NotEqual(t, io.EOF, err, name)
+		require.NoError(t, source.close())
+	}
+
+	_, err := newCSVDataset(filepath.Join(t.TempDir(), "missing.csv"), 0, false, nil)
+	assert.True(t, os.IsNotExist(err))
+	_, err = newBinaryDataset(filepath.Join(t.TempDir(), "missing.bin"), 0)
+	assert.Error(t, err, "Binary records need inputs")
+}
+
+func TestTrainStreamMatchesTrain(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var config trainingConfig = trainingConfig{epochs: 5, batchSize: 4, learningRateStart: 0.5, momentum: 0.5}
+
+	var inMemory network = newTrainingTestNetwork(7)
+	var inMemoryRecorder *metricsRecorder = &metricsRecorder{}
+	var memoryTrainer trainer = newTrainer(&inMemory, config, inMemoryRecorder)
+	require.NoError(t, memoryTrainer.train(inputs, targets))
+
+	var streamed network = newTrainingTestNetwork(7)
+	var streamedRecorder *metricsRecorder = &metricsRecorder{}
+	var source *sliceDataset = newSliceDataset(inputs, targets)
+	var streamTrainer trainer = newTrainer(&streamed, config, streamedRecorder)
+	require.NoError(t, streamTrainer.trainStream(source))
+
+	assert.Equal(t, 4, source.resets, "The dataset should be reset before every epoch but the first")
+	assert.Equal(t, snapshotParameters(&inMemory), snapshotParameters(&streamed), "Streaming the same batches should train the same parameters")
+	assert.InDelta(t, inMemoryRecorder.last["loss"], streamedRecorder.last["loss"], 1e-12)
+}
+
+func TestTrainStreamShuffled(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var config trainingConfig = trainingConfig{epochs: 3, batchSize: 2, learningRateStart: 0.5, shuffle: true, shuffleBufferSize: 3, seed: 4}
+	var snapshots [][]vector
+
+	for run := 0; run < 2; run++ {
+		var net network = newTrainingTestNetwork(7)
+		var streamTrainer trainer = newTrainer(&net, config)
+		require.NoError(t, streamTrainer.trainStream(newSliceDataset(inputs, targets)), fmt.Sprint(run))
+		snapshots = append(snapshots, snapshotParameters(&net))
+	}
+
+	assert.Equal(t, snapshots[0], snapshots[1], "Shuffled streaming should be deterministic for a seed")
+
+	var net network = newTrainingTestNetwork(7)
+	var streamTrainer trainer = newTrainer(&net, trainingConfig{epochs: 1, batchSize: 2})
+	assert.Error(t, streamTrainer.trainStream(newSliceDataset(nil, nil)), "An empty dataset can not be trained on")
+
+	streamTrainer = newTrainer(&net, trainingConfig{epochs: 1})
+	assert.Panics(t, func() { streamTrainer.trainStream(newSliceDataset(inputs, targets)) }, "Should panic with full batch")
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	encodingNumeric string = "numeric"
+	encodingOneHot  string = "oneHot"
+	encodingOrdinal string = "ordinal"
+)
+
+// Unknown category policies. With unknownCategoryIgnore a one-hot encoder outputs all zeros and an ordinal encoder
+// outputs -1 for categories it was not fitted on.
+const (
+	unknownCategoryFail   string = "error"
+	unknownCategoryIgnore string = "ignore"
+)
+
+// columnEncoder turns one CSV column into one or more numeric feature columns.
+type columnEncoder interface {
+	fit(values []string)
+	transform(values []string) (matrix, error)
+	outputWidth() int
+}
+
+// numericColumn parses values as floats.
+type numericColumn struct{}
+
+func (numericColumn) fit(values []string) {}
+
+func (numericColumn) transform(values []string) (matrix, error) {
+	var output matrix = make(matrix, len(values))
+
+	for index, value := range values {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", index, err)
+		}
+
+		output[index] = vector{parsed}
+	}
+
+	return output, nil
+}
+
+func (numericColumn) outputWidth() int {
+	return 1
+}
+
+// categoryIndex maps categories to their position. Categories are sorted when fitted, unless they were given
+// explicitly to keep a meaningful order such as low, medium, high.
+type categoryIndex struct {
+	categories    []string
+	indexes       map[string]int
+	fixed         bool
+	handleUnknown string
+}
+
+func newCategoryIndex(handleUnknown string, categories []string) categoryIndex {
+	if handleUnknown != unknownCategoryFail && handleUnknown != unknownCategoryIgnore {
+		panic(fmt.Sprintf("Unknown category policy %q", handleUnknown))
+	}
+
+	var c categoryIndex = categoryIndex{handleUnknown: handleUnknown, fixed: len(categories) > 0}
+	c.setCategories(categories)
+	return c
+}
+
+func (c *categoryIndex) setCategories(categories []string) {
+	c.categories = categories
+	c.indexes = make(map[string]int, len(categories))
+
+	for index, category := range categories {
+		if _, ok := c.indexes[category]; ok {
+			panic(fmt.Sprintf("Duplicate category %q", category))
+		}
+
+		c.indexes[category] = index
+	}
+}
+
+func (c *categoryIndex) fit(values []string) {
+	if c.fixed {
+		return
+	}
+
+	var seen map[string]bool = map[string]bool{}
+	var categories []string
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			categories = append(categories, value)
+		}
+	}
+
+	sort.Strings(categories)
+	c.setCategories(categories)
+}
+
+// lookup returns the index of value, or -1 if it is unknown and unknown categories are ignored.
+func (c categoryIndex) lookup(value string) (int, error) {
+	if index, ok := c.indexes[value]; ok {
+		return index, nil
+	}
+
+	if c.handleUnknown == unknownCategoryIgnore {
+		return -1, nil
+	}
+
+	return -1, &unknownCategoryError{category: value, message: fmt.Sprintf("unknown category %q, known categories are %v", value, c.categories)}
+}
+
+// oneHotEncoder outputs one column per category, 1 for the row's category and 0 for all others.
+type oneHotEncoder struct {
+	categoryIndex
+}
+
+func newOneHotEncoder(handleUnknown string, categories ...string) *oneHotEncoder {
+	return &oneHotEncoder{categoryIndex: newCategoryIndex(handleUnknown, categories)}
+}
+
+func (o *oneHotEncoder) transform(values []string) (matrix, error) {
+	var output matrix = make(matrix, len(values))
+
+	for rowIndex, value := range values {
+		index, err := o.lookup(value)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", rowIndex, err)
+		}
+
+		output[rowIndex] = make(vector, len(o.categories))
+		if index >= 0 {
+			output[rowIndex][index] = 1
+		}
+	}
+
+	return output, nil
+}
+
+func (o *oneHotEncoder) outputWidth() int {
+	return len(o.categories)
+}
+
+// ordinalEncoder outputs the index of the row's category as a single column.
+type ordinalEncoder struct {
+	categoryIndex
+}
+
+func newOrdinalEncoder(handleUnknown string, categories ...string) *ordinalEncoder {
+	return &ordinalEncoder{categoryIndex: newCategoryIndex(handleUnknown, categories)}
+}
+
+func (o *ordinalEncoder) transform(values []string) (matrix, error) {
+	var output matrix = make(matrix, len(values))
+
+	for rowIndex, value := range values {
+		index, err := o.lookup(value)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", rowIndex, err)
+		}
+
+		output[rowIndex] = vector{float64(index)}
+	}
+
+	return output, nil
+}
+
+func (o *ordinalEncoder) outputWidth() int {
+	return 1
+}
+
+// labelEncoder maps class names to the target indexes crossentropy expects and back. Unknown class names are
+// always an error. Its classes can be used as network labels.
+type labelEncoder struct {
+	categoryIndex
+}
+
+func newLabelEncoder(classes ...string) *labelEncoder {
+	return &labelEncoder{categoryIndex: newCategoryIndex(unknownCategoryFail, classes)}
+}
+
+func (l *labelEncoder) transform(values []string) ([]int, error) {
+	var targets []int = make([]int, len(values))
+
+	for rowIndex, value := range values {
+		index, err := l.lookup(value)
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", rowIndex, err)
+		}
+
+		targets[rowIndex] = index
+	}
+
+	return targets, nil
+}
+
+func (l *labelEncoder) inverseTransform(targets []int) []string {
+	var values []string = make([]string, len(targets))
+
+	for index, target := range targets {
+		if target < 0 || target >= len(l.categories) {
+			panic(fmt.Sprintf("Target %d is out of bounds of class count %d", target, len(l.categories)))
+		}
+
+		values[index] = l.categories[target]
+	}
+
+	return values
+}
+
+// featureEncoder encodes every column of string records with its own columnEncoder and concatenates the results.
+type featureEncoder struct {
+	columns []columnEncoder
+}
+
+func newFeatureEncoder(columns ...columnEncoder) *featureEncoder {
+	if len(columns) == 0 {
+		panic("Can not create feature encoder without columns")
+	}
+
+	return &featureEncoder{columns: columns}
+}
+
+// recordColumn returns column columnIndex of records.
+func recordColumn(records [][]string, columnIndex int) []string {
+	var values []string = make([]string, len(records))
+	for index, record := range records {
+		values[index] = record[columnIndex]
+	}
+
+	return values
+}
+
+func (f *featureEncoder) checkRecords(records [][]string) error {
+	for index, record := range records {
+		if len(record) != len(f.columns) {
+			return newShapeMismatchError(len(f.columns), len(record), "row %d has %d columns but the encoder expects %d", index, len(record), len(f.columns))
+		}
+	}
+
+	return nil
+}
+
+func (f *featureEncoder) fit(records [][]string) error {
+	if err := f.checkRecords(records); err != nil {
+		return err
+	}
+
+	for columnIndex, encoder := range f.columns {
+		encoder.fit(recordColumn(records, columnIndex))
+	}
+
+	return nil
+}
+
+func (f *featureEncoder) transform(records [][]string) (matrix, error) {
+	if err := f.checkRecords(records); err != nil {
+		return nil, err
+	}
+
+	var output matrix = make(matrix, len(records))
+	for index := range output {
+		output[index] = make(vector, 0, f.outputWidth())
+	}
+
+	for columnIndex, encoder := range f.columns {
+		encoded, err := encoder.transform(recordColumn(records, columnIndex))
+		if err != nil {
+			return nil, fmt.Errorf("column %d: %w", columnIndex, err)
+		}
+
+		for index, row := range encoded {
+			output[index] = append(output[index], row...)
+		}
+	}
+
+	return output, nil
+}
+
+func (f *featureEncoder) fitTransform(records [][]string) (matrix, error) {
+	if err := f.fit(records); err != nil {
+		return nil, err
+	}
+
+	return f.transform(records)
+}
+
+func (f *featureEncoder) outputWidth() int {
+	var width int = 0
+	for _, encoder := range f.columns {
+		width += encoder.outputWidth()
+	}
+
+	return width
+}
+
+type columnEncoderSpec struct {
+	Encoding      string   `json:"encoding"`
+	Categories    []string `json:"categories,omitempty"`
+	HandleUnknown string   `json:"handleUnknown,omitempty"`
+}
+
+// encodingSpec is the serialized form of a fitted featureEncoder and labelEncoder.
+type encodingSpec struct {
+	Columns []columnEncoderSpec `json:"columns"`
+	Classes []string            `json:"classes,omitempty"`
+}
+
+func newEncodingSpec(features *featureEncoder, labels *labelEncoder) (encodingSpec, error) {
+	var spec encodingSpec
+
+	for index, encoder := range features.columns {
+		switch typed := encoder.(type) {
+		case numericColumn:
+			spec.Columns = append(spec.Columns, columnEncoderSpec{Encoding: encodingNumeric})
+		case *oneHotEncoder:
+			spec.Columns = append(spec.Columns, columnEncoderSpec{Encoding: encodingOneHot, Categories: typed.categories, HandleUnknown: typed.handleUnknown})
+		case *ordinalEncoder:
+			spec.Columns = append(spec.Columns, columnEncoderSpec{Encoding: encodingOrdinal, Categories: typed.categories, HandleUnknown: typed.handleUnknown})
+		default:
+			return encodingSpec{}, fmt.Errorf("column %d: can not serialize encoder of type %T", index, encoder)
+		}
+	}
+
+	if labels != nil {
+		spec.Classes = labels.categories
+	}
+
+	return spec, nil
+}
+
+// duplicateCategory returns the first category that appears twice in categories.
+func duplicateCategory(categories []string) (string, bool) {
+	var seen map[string]bool = make(map[string]bool, len(categories))
+	for _, category := range categories {
+		if seen[category] {
+			return category, true
+		}
+		seen[category] = true
+	}
+
+	return "", false
+}
+
+func (s encodingSpec) build() (*featureEncoder, *labelEncoder, error) {
+	if len(s.Columns) == 0 {
+		return nil, nil, fmt.Errorf("encoding has no columns")
+	}
+
+	var columns []columnEncoder = make([]columnEncoder, len(s.Columns))
+	for index, column := range s.Columns {
+		if column.Encoding != encodingNumeric && column.HandleUnknown != unknownCategoryFail && column.HandleUnknown != unknownCategoryIgnore {
+			return nil, nil, fmt.Errorf("column %d: unknown category policy %q", index, column.HandleUnknown)
+		}
+
+		if category, ok := duplicateCategory(column.Categories); ok {
+			return nil, nil, fmt.Errorf("column %d: duplicate category %q", index, category)
+		}
+
+		switch column.Encoding {
+		case encodingNumeric:
+			columns[index] = numericColumn{}
+		case encodingOneHot:
+			columns[index] = newOneHotEncoder(column.HandleUnknown, column.Categories...)
+		case encodingOrdinal:
+			columns[index] = newOrdinalEncoder(column.HandleUnknown, column.Categories...)
+		default:
+			return nil, nil, fmt.Errorf("column %d: unknown encoding %q", index, column.Encoding)
+		}
+	}
+
+	if class, ok := duplicateCategory(s.Classes); ok {
+		return nil, nil, fmt.Errorf("duplicate class %q", class)
+	}
+
+	var labels *labelEncoder
+	if len(s.Classes) != 0 {
+		labels = newLabelEncoder(s.Classes...)
+	}
+
+	return newFeatureEncoder(columns...), labels, nil
+}
+
+// saveEncoding writes fitted encoders to path. labels may be nil.
+func saveEncoding(path string, features *featureEncoder, labels *labelEncoder) error {
+	spec, err := newEncodingSpec(features, labels)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// loadEncoding reads encoders written by saveEncoding. The label encoder is nil if none was saved.
+func loadEncoding(path string) (*featureEncoder, *labelEncoder, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var spec encodingSpec
+	if err = json.Unmarshal(data, &spec); err != nil {
+		return nil, nil, fmt.Errorf("can not parse encoding file %s: %w", path, err)
+	}
+
+	return spec.build()
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encoderTestRecords has a numeric, a nominal and an ordinal column.
+func encoderTestRecords() [][]string {
+	return [][]string{
+		{"1.5", "red", "low"},
+		{"2", "green", "high"},
+		{"-3", "blue", "medium"},
+		{"0", "red", "low"},
+	}
+}
+
+func TestFeatureEncoderTransform(t *testing.T) {
+	var encoder *featureEncoder = newFeatureEncoder(numericColumn{}, newOneHotEncoder(unknownCategoryFail), newOrdinalEncoder(unknownCategoryFail, "low", "medium", "high"))
+
+	encoded, err := encoder.fitTransform(encoderTestRecords())
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, encoder.outputWidth())
+	assert.Equal(t, matrix{
+		{1.5, 0, 0, 1, 0},
+		{2, 0, 1, 0, 2},
+		{-3, 1, 0, 0, 1},
+		{0, 0, 0, 1, 0},
+	}, encoded, "One-hot categories should be sorted and ordinal categories kept in the given order")
+}
+
+func TestFeatureEncoderUnknownCategories(t *testing.T) {
+	var strict *featureEncoder = newFeatureEncoder(numericColumn{}, newOneHotEncoder(unknownCategoryFail), newOrdinalEncoder(unknownCategoryFail))
+	_, err := strict.fitTransform(encoderTestRecords())
+	require.NoError(t, err)
+
+	_, err = strict.transform([][]string{{"1", "purple", "low"}})
+	var categoryErr *unknownCategoryError
+	require.True(t, errors.As(err, &categoryErr), "Unknown categories should be an error")
+	assert.Equal(t, "purple", categoryErr.category)
+
+	var lenient *featureEncoder = newFeatureEncoder(numericColumn{}, newOneHotEncoder(unknownCategoryIgnore), newOrdinalEncoder(unknownCategoryIgnore))
+	_, err = lenient.fitTransform(encoderTestRecords())
+	require.NoError(t, err)
+
+	encoded, err := lenient.transform([][]string{{"1", "purple", "extreme"}})
+	require.NoError(t, err)
+	assert.Equal(t, matrix{{1, 0, 0, 0, -1}}, encoded)
+}
+
+func TestFeatureEncoderErrors(t *testing.T) {
+	var encoder *featureEncoder = newFeatureEncoder(numericColumn{}, newOneHotEncoder(unknownCategoryFail))
+
+	var shapeErr *shapeMismatchError
+	assert.True(t, errors.As(encoder.fit([][]string{{"1"}}), &shapeErr), "Records with the wrong column count should be rejected")
+
+	_, err := encoder.fitTransform([][]string{{"one", "red"}})
+	assert.Error(t, err, "Numeric columns should reject values that are not numbers")
+
+	assert.Panics(t, func() { newFeatureEncoder() }, "Should panic without columns")
+	assert.Panics(t, func() { newOneHotEncoder("skip") }, "Should panic with unknown policy")
+	assert.Panics(t, func() { newLabelEncoder("a", "a") }, "Should panic with duplicate categories")
+}
+
+func TestLabelEncoder(t *testing.T) {
+	var encoder *labelEncoder = newLabelEncoder()
+	encoder.fit([]string{"virginica", "setosa", "versicolor", "setosa"})
+
+	targets, err := encoder.transform([]string{"setosa", "versicolor", "virginica"})
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 1, 2}, targets)
+	assert.Equal(t, []string{"virginica", "setosa"}, encoder.inverseTransform([]int{2, 0}))
+
+	_, err = encoder.transform([]string{"daisy"})
+	var categoryErr *unknownCategoryError
+	assert.True(t, errors.As(err, &categoryErr), "Unknown class names should be an error")
+	assert.Panics(t, func() { encoder.inverseTransform([]int{3}) }, "Should panic with out of range target")
+}
+
+func TestSaveLoadEncoding(t *testing.T) {
+	var features *featureEncoder = newFeatureEncoder(numericColumn{}, newOneHotEncoder(unknownCategoryIgnore), newOrdinalEncoder(unknownCategoryFail, "low", "medium", "high"))
+	_, err := features.fitTransform(encoderTestRecords())
+	require.NoError(t, err)
+	var labels *labelEncoder = newLabelEncoder("no", "yes")
+	var path string = filepath.Join(t.TempDir(), "encoding.json")
+
+	require.NoError(t, saveEncoding(path, features, labels))
+
+	loadedFeatures, loadedLabels, err := loadEncoding(path)
+	require.NoError(t, err)
+
+	var records [][]string = append(encoderTestRecords(), []string{"4", "purple", "medium"})
+	expected, err := features.transform(records)
+	require.NoError(t, err)
+	actual, err := loadedFeatures.transform(records)
+	require.NoError(t, err)
+	assert.Equal(t, expected, actual, "Loaded encoders should encode like the saved ones")
+	assert.Equal(t, labels.categories, loadedLabels.categories)
+
+	require.NoError(t, saveEncoding(path, features, nil))
+	_, loadedLabels, err = loadEncoding(path)
+	require.NoError(t, err)
+	assert.Nil(t, loadedLabels)
+}
+
+func TestLoadEncodingErrors(t *testing.T) {
+	var cases map[string]string = map[string]string{
+		"invalid json":     `{`,
+		"no columns":       `{"columns": []}`,
+		"unknown encoding": `{"columns": [{"encoding": "hash"}]}`,
+		"unknown policy":   `{"columns": [{"encoding": "oneHot", "categories": ["a"], "handleUnknown": "skip"}]}`,
+		"duplicate column": `{"columns": [{"encoding": "ordinal", "categories": ["a", "b", "a"], "handleUnknown": "error"}]}`,
+		"duplicate class":  `{"columns": [{"encoding": "numeric"}], "classes": ["x", "x"]}`,
+	}
+
+	for name, content := range cases {
+		var path string = filepath.Join(t.TempDir(), "encoding.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+
+		_, _, err := loadEncoding(path)
+		assert.Error(t, err, name)
+	}
+
+	_, _, err := loadEncoding(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
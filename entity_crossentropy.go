@@ -13,14 +13,24 @@ type crossentropy struct {
 }
 
 func (c *crossentropy) forward(input matrix, targets []int) vector {
+	output, err := c.tryForward(input, targets)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return output
+}
+
+// tryForward is forward returning a shapeMismatchError or targetOutOfRangeError instead of panicking.
+func (c *crossentropy) tryForward(input matrix, targets []int) (vector, error) {
 	var inputLen int = len(input)
 	var targetsLen int = len(targets)
 
 	if inputLen != targetsLen {
-		panic(fmt.Sprintf(
+		return nil, newShapeMismatchError(inputLen, targetsLen,
 			"Crossentropy targets length %d does not match input batch size %d. There must be one target value per row in the inputs batch matrix",
-			inputLen, targetsLen,
-		))
+			targetsLen, inputLen,
+		)
 	}
 
 	const safetyMargin float64 = 1e-7
@@ -31,7 +41,12 @@ func (c *crossentropy) forward(input matrix, targets []int) vector {
 		var rowLength int = len(inputRow)
 
 		if targetIndex <= -1 || targetIndex >= rowLength {
-			panic(fmt.Sprintf("A crossentropy target index %d is out of bounds of its corresponding input row length %d", targetIndex, rowLength))
+			return nil, &targetOutOfRangeError{
+				sample:     index,
+				target:     targetIndex,
+				classCount: rowLength,
+				message:    fmt.Sprintf("A crossentropy target index %d is out of bounds of its corresponding input row length %d", targetIndex, rowLength),
+			}
 		}
 
 		var targetValue float64 = inputRow[targetIndex]
@@ -44,7 +59,7 @@ func (c *crossentropy) forward(input matrix, targets []int) vector {
 	c.lastInput = input
 	c.lastTargets = targets
 	c.lastOutput = output
-	return output
+	return output, nil
 }
 
 func (c crossentropy) getInputDerivatives() matrix {
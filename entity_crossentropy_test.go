@@ -18,7 +18,10 @@ func TestCrossentropyForwardPanics(t *testing.T) {
 
 	input = matrix{{1, 2}, {1, 2}}
 	targets = []int{1}
-	assert.Panics(tryForward, "Should panic with mismatch between input and targets length")
+	assert.PanicsWithValue(
+		"Crossentropy targets length 1 does not match input batch size 2. There must be one target value per row in the inputs batch matrix",
+		tryForward, "Should panic with mismatch between input and targets length",
+	)
 
 	targets = []int{0, 2}
 	assert.Panics(tryForward, "Should panic with targets value out of bounds of corresponding input row")
@@ -79,3 +82,15 @@ func TestCrossentropyBackwardDerivativeInput(t *testing.T) {
 
 	assert.Equal(t, expectedInputDerivatives, actualInputDerivatives, "Crossentropy back propigate produces wrong input derivatives")
 }
+
+func TestCrossentropyGradientCheck(t *testing.T) {
+	var inputs matrix = matrix{
+		{0.7, 0.2, 0.1},
+		{0.4, 0.5, 0.1},
+	}
+
+	var c crossentropy = crossentropy{}
+	var report gradientCheckReport = checkLossGradients(&c, inputs, []int{0, 2}, defaultGradientCheckEpsilon)
+
+	assert.Empty(t, report.failures(1e-6), report.String())
+}
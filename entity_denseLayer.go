@@ -0,0 +1,320 @@
+package main
+
+import "math/rand"
+
+// denseLayer is the performance oriented counterpart of layer. Instead of owning a neuron per output it stores
+// all weights in a single row-major matrix (one row per neuron) and runs forward and backward as matrix multiplies.
+type denseLayer struct {
+	layerSize         int
+	inputCount        int
+	weights           denseMatrix
+	biases            vector
+	lastInput         denseMatrix
+	derivativeWeights denseMatrix
+	derivativeBiases  vector
+	inputDerivatives  matrix
+}
+
+func newDenseLayer(layerSize, inputCount int) denseLayer {
+	l, err := tryNewDenseLayer(layerSize, inputCount)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return l
+}
+
+// tryNewDenseLayer is newDenseLayer returning an invalidDimensionError instead of panicking.
+func tryNewDenseLayer(layerSize, inputCount int) (denseLayer, error) {
+	return tryNewDenseLayerWithRandom(layerSize, inputCount, nil)
+}
+
+// newDenseLayerWithRandom is newDenseLayer drawing the initial parameters from random instead of the global source.
+func newDenseLayerWithRandom(layerSize, inputCount int, random *rand.Rand) denseLayer {
+	l, err := tryNewDenseLayerWithRandom(layerSize, inputCount, random)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return l
+}
+
+// tryNewDenseLayerWithRandom is newDenseLayerWithRandom returning an invalidDimensionError instead of panicking.
+func tryNewDenseLayerWithRandom(layerSize, inputCount int, random *rand.Rand) (denseLayer, error) {
+	if layerSize <= 0 {
+		return denseLayer{}, newInvalidDimensionError("layer size", layerSize, "Can not create layer with size %d", layerSize)
+	}
+
+	if inputCount <= 0 {
+		return denseLayer{}, newInvalidDimensionError("input count", inputCount, "Can not create layer with input count %d", inputCount)
+	}
+
+	var weights denseMatrix = newDenseMatrix(layerSize, inputCount)
+	var biases vector = make(vector, layerSize)
+
+	// Values are drawn in the same order newNeuron draws them so both layer types initialize identically for a given seed
+	for neuronIndex := range biases {
+		biases[neuronIndex] = randRangeFloat64From(random, 0, 1)
+
+		var neuronWeights vector = weights.row(neuronIndex)
+		for weightIndex := range neuronWeights {
+			neuronWeights[weightIndex] = randRangeFloat64From(random, 0.1, 1)
+		}
+	}
+
+	return denseLayer{layerSize: layerSize, inputCount: inputCount, weights: weights, biases: biases}, nil
+}
+
+func newDenseLayerExplicit(weights matrix, biases vector) denseLayer {
+	l, err := tryNewDenseLayerExplicit(weights, biases)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return l
+}
+
+// tryNewDenseLayerExplicit is newDenseLayerExplicit returning an invalidDimensionError or shapeMismatchError
+// instead of panicking.
+func tryNewDenseLayerExplicit(weights matrix, biases vector) (denseLayer, error) {
+	var neuronCount = len(weights)
+	var biasCount = len(biases)
+
+	if neuronCount == 0 {
+		return denseLayer{}, newInvalidDimensionError("neuron count", neuronCount, "Can not create layer with 0 neurons")
+	}
+
+	if biasCount == 0 {
+		return denseLayer{}, newInvalidDimensionError("bias count", biasCount, "Can not create layer with 0 biases")
+	}
+
+	if neuronCount != biasCount {
+		return denseLayer{}, newShapeMismatchError(neuronCount, biasCount, "Layer neuron count %d does not match layer bias count %d", neuronCount, biasCount)
+	}
+
+	var firstWeightSetLen int = len(weights[0])
+	for index := range weights {
+		if len(weights[index]) != firstWeightSetLen {
+			return denseLayer{}, newShapeMismatchError(firstWeightSetLen, len(weights[index]), "Found neurons in layer with differint input counts")
+		}
+	}
+
+	if firstWeightSetLen == 0 {
+		return denseLayer{}, newInvalidDimensionError("input count", firstWeightSetLen, "Can not create layer with input count 0")
+	}
+
+	var layerBiases vector = make(vector, biasCount)
+	copy(layerBiases, biases)
+
+	return denseLayer{
+		layerSize:  neuronCount,
+		inputCount: firstWeightSetLen,
+		weights:    denseMatrixFromMatrix(weights),
+		biases:     layerBiases,
+	}, nil
+}
+
+func newDenseLayerFromLayer(l layer) denseLayer {
+	var weights matrix = make(matrix, len(l.neurons))
+	var biases vector = make(vector, len(l.neurons))
+
+	for index, n := range l.neurons {
+		weights[index] = n.weights
+		biases[index] = n.bias
+	}
+
+	return newDenseLayerExplicit(weights, biases)
+}
+
+func (l *denseLayer) forward(input matrix) matrix {
+	output, err := l.tryForward(input)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return output
+}
+
+// tryForward is forward returning an invalidDimensionError or shapeMismatchError instead of panicking.
+func (l *denseLayer) tryForward(input matrix) (matrix, error) {
+	if err := l.checkInput(input); err != nil {
+		return nil, err
+	}
+
+	var denseInput denseMatrix = denseMatrixFromMatrix(input)
+	var output denseMatrix = l.denseForward(denseInput)
+
+	l.lastInput = denseInput
+	return output.toMatrix(), nil
+}
+
+func (l denseLayer) predict(input matrix) matrix {
+	output, err := l.tryPredict(input)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return output
+}
+
+func (l denseLayer) tryPredict(input matrix) (matrix, error) {
+	if err := l.checkInput(input); err != nil {
+		return nil, err
+	}
+
+	return l.denseForward(denseMatrixFromMatrix(input)).toMatrix(), nil
+}
+
+func (l denseLayer) checkInput(input matrix) error {
+	if len(input) == 0 {
+		return newInvalidDimensionError("batch size", 0, "Can not forward layer with empty input batch")
+	}
+
+	for _, inputSample := range input {
+		if l.inputCount != len(inputSample) {
+			return newShapeMismatchError(l.inputCount, len(inputSample), "Layer input count %d does not match len of provided input %d", l.inputCount, len(inputSample))
+		}
+	}
+
+	return nil
+}
+
+func (l denseLayer) denseForward(denseInput denseMatrix) denseMatrix {
+	var output denseMatrix = newDenseMatrix(denseInput.rows, l.layerSize)
+	gemm(false, true, denseInput, l.weights, &output)
+
+	for rowIndex := 0; rowIndex < output.rows; rowIndex++ {
+		var outputRow vector = output.row(rowIndex)
+		for neuronIndex, bias := range l.biases {
+			outputRow[neuronIndex] += bias
+		}
+	}
+
+	return output
+}
+
+func (l denseLayer) getInputDerivatives() matrix {
+	return l.inputDerivatives
+}
+
+func (l *denseLayer) backward(forwardInputDerivatives matrix) {
+	if err := l.tryBackward(forwardInputDerivatives); err != nil {
+		panic(err.Error())
+	}
+}
+
+// tryBackward is backward returning a missingForwardStateError or shapeMismatchError instead of panicking.
+func (l *denseLayer) tryBackward(forwardInputDerivatives matrix) error {
+	var lastInputLen int = l.lastInput.rows
+	var forwardInputDerivativesLen int = len(forwardInputDerivatives)
+
+	if lastInputLen == 0 {
+		return newMissingForwardStateError("dense layer", "Layer has not previous input. Can not backpropigate")
+	}
+
+	if forwardInputDerivativesLen != lastInputLen {
+		return newShapeMismatchError(lastInputLen, forwardInputDerivativesLen,
+			"Forward derivatives length %d does not match previous inputs length %d. There must be a row in the forward derivatives matrix for each input sample in the previous input",
+			forwardInputDerivativesLen, lastInputLen,
+		)
+	}
+
+	for _, forwardDerivativeRow := range forwardInputDerivatives {
+		var forwardDerivativeRowLen int = len(forwardDerivativeRow)
+		if forwardDerivativeRowLen != l.layerSize {
+			return newShapeMismatchError(l.layerSize, forwardDerivativeRowLen, "The passed forward input derivative contains a row whose length %d does not match the layer size %d", forwardDerivativeRowLen, l.layerSize)
+		}
+	}
+
+	var forwardDerivatives denseMatrix = denseMatrixFromMatrix(forwardInputDerivatives)
+	var sampleCount float64 = float64(lastInputLen)
+
+	var derivativeWeights denseMatrix = newDenseMatrix(l.layerSize, l.inputCount)
+	gemm(true, false, forwardDerivatives, l.lastInput, &derivativeWeights)
+	for index := range derivativeWeights.data {
+		derivativeWeights.data[index] /= sampleCount
+	}
+
+	var derivativeBiases vector = make(vector, l.layerSize)
+	for rowIndex := 0; rowIndex < forwardDerivatives.rows; rowIndex++ {
+		for neuronIndex, derivativeValue := range forwardDerivatives.row(rowIndex) {
+			derivativeBiases[neuronIndex] += derivativeValue
+		}
+	}
+	for neuronIndex := range derivativeBiases {
+		derivativeBiases[neuronIndex] /= sampleCount
+	}
+
+	var inputDerivatives denseMatrix = newDenseMatrix(lastInputLen, l.inputCount)
+	gemm(false, false, forwardDerivatives, l.weights, &inputDerivatives)
+
+	l.derivativeWeights = derivativeWeights
+	l.derivativeBiases = derivativeBiases
+	l.inputDerivatives = inputDerivatives.toMatrix()
+	return nil
+}
+
+func (l denseLayer) parameterCount() int {
+	return l.layerSize * (l.inputCount + 1)
+}
+
+// getParameters flattens the parameters neuron by neuron, each neuron contributing its weights followed by its bias.
+func (l denseLayer) getParameters() vector {
+	var parameters vector = make(vector, 0, l.parameterCount())
+
+	for neuronIndex, bias := range l.biases {
+		parameters = append(parameters, l.weights.row(neuronIndex)...)
+		parameters = append(parameters, bias)
+	}
+
+	return parameters
+}
+
+func (l *denseLayer) setParameters(parameters vector) {
+	checkParameterCount(l, parameters)
+
+	for neuronIndex := range l.biases {
+		var offset int = neuronIndex * (l.inputCount + 1)
+
+		copy(l.weights.row(neuronIndex), parameters[offset:offset+l.inputCount])
+		l.biases[neuronIndex] = parameters[offset+l.inputCount]
+	}
+}
+
+func (l denseLayer) getParameterGradients() vector {
+	var gradients vector = make(vector, l.parameterCount())
+
+	if l.derivativeWeights.rows == 0 {
+		return gradients
+	}
+
+	for neuronIndex, derivativeBias := range l.derivativeBiases {
+		var offset int = neuronIndex * (l.inputCount + 1)
+
+		copy(gradients[offset:offset+l.inputCount], l.derivativeWeights.row(neuronIndex))
+		gradients[offset+l.inputCount] = derivativeBias
+	}
+
+	return gradients
+}
+
+func (l *denseLayer) setParameterGradients(gradients vector) {
+	checkParameterCount(l, gradients)
+
+	var derivativeWeights denseMatrix = newDenseMatrix(l.layerSize, l.inputCount)
+	var derivativeBiases vector = make(vector, l.layerSize)
+
+	for neuronIndex := range derivativeBiases {
+		var offset int = neuronIndex * (l.inputCount + 1)
+
+		copy(derivativeWeights.row(neuronIndex), gradients[offset:offset+l.inputCount])
+		derivativeBiases[neuronIndex] = gradients[offset+l.inputCount]
+	}
+
+	l.derivativeWeights = derivativeWeights
+	l.derivativeBiases = derivativeBiases
+}
+
+func (l denseLayer) describeParameter(index int) string {
+	return describeNeuronParameter(index, l.inputCount)
+}
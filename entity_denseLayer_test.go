@@ -0,0 +1,207 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func randomMatrix(rows, cols int) matrix {
+	var m matrix = make(matrix, rows)
+
+	for rowIndex := range m {
+		m[rowIndex] = make(vector, cols)
+		for colIndex := range m[rowIndex] {
+			m[rowIndex][colIndex] = randRangeFloat64(-1, 1)
+		}
+	}
+
+	return m
+}
+
+func TestNewDenseLayerPanics(t *testing.T) {
+	var assert *assert.Assertions = assert.New(t)
+
+	assert.Panics(func() { newDenseLayer(0, 1) }, "Should panic with layer size 0")
+	assert.Panics(func() { newDenseLayer(1, -1) }, "Should panic with negative input count")
+
+	assert.Panics(func() { newDenseLayerExplicit(matrix{}, vector{1}) }, "Should panic with 0 neurons")
+	assert.Panics(func() { newDenseLayerExplicit(matrix{{1}}, vector{}) }, "Should panic with 0 biases")
+	assert.Panics(func() { newDenseLayerExplicit(matrix{{1}, {2}}, vector{1}) }, "Should panic with mismatch between neuron and bias counts")
+	assert.Panics(func() { newDenseLayerExplicit(matrix{{1, 2}, {1}}, vector{1, 1}) }, "Should panic with neurons that have different input counts")
+}
+
+func TestNewDenseLayerMatchesLayerInitialization(t *testing.T) {
+	rand.Seed(7)
+	var l layer = newLayer(4, 3)
+
+	rand.Seed(7)
+	var d denseLayer = newDenseLayer(4, 3)
+
+	require.Equal(t, newDenseLayerFromLayer(l), d, "Dense layer should draw the same initial values as layer for the same seed")
+}
+
+func TestDenseLayerForward(t *testing.T) {
+	var biases vector = vector{1, 2, 4}
+	var weights matrix = matrix{
+		{2, 2, 4},
+		{6, 4, 8},
+		{12, 1, 1},
+	}
+	var l denseLayer = newDenseLayerExplicit(weights, biases)
+
+	var inputs matrix = matrix{
+		{2, 2, 2},
+		{1, 3, 2},
+	}
+
+	var expectedOutput matrix = matrix{
+		{17, 38, 32},
+		{17, 36, 21},
+	}
+
+	assert.Equal(t, expectedOutput, l.forward(inputs), "Dense layer forward returns wrong output for multiple input rows")
+	assert.Panics(t, func() { l.forward(matrix{{1, 2}}) }, "Should panic with input rows not matching the input count")
+}
+
+func TestDenseLayerBackwardPanics(t *testing.T) {
+	var l denseLayer = newDenseLayer(3, 3)
+	assert.Panics(t, func() { l.backward(matrix{{1, 1, 1}}) }, "Should panic on back propigate with no previous input")
+
+	l.forward(matrix{{1, 1, 1}, {1, 1, 1}})
+	assert.Panics(t, func() { l.backward(matrix{{1, 1, 1}}) }, "Should panic on back propigate with forward derivative length not matching input length")
+	assert.Panics(t, func() { l.backward(matrix{{1, 1, 1}, {1, 1}}) }, "Should panic on back propigate with forward derivative row length not matching layer size")
+}
+
+func TestDenseLayerBackward(t *testing.T) {
+	var biases vector = vector{1, 2, 4}
+	var weights matrix = matrix{
+		{2, 2, 4},
+		{6, 4, 8},
+		{12, 1, 1},
+	}
+	var l denseLayer = newDenseLayerExplicit(weights, biases)
+
+	l.forward(matrix{
+		{2, 2, 2},
+		{1, 3, 2},
+	})
+	l.backward(matrix{
+		{1, 1, 1},
+		{2, 1, 1},
+	})
+
+	var expectedInputDerivatives matrix = matrix{
+		{20, 7, 13},
+		{22, 9, 17},
+	}
+	var expectedDerivativeWeights matrix = matrix{
+		{2, 4, 3},
+		{1.5, 2.5, 2},
+		{1.5, 2.5, 2},
+	}
+
+	assert.Equal(t, expectedInputDerivatives, l.getInputDerivatives(), "Dense layer backwards produces wrong input derivatives")
+	assert.Equal(t, expectedDerivativeWeights, l.derivativeWeights.toMatrix(), "Dense layer backwards produces wrong derivative weights")
+	assert.Equal(t, vector{1.5, 1, 1}, l.derivativeBiases, "Dense layer backwards produces wrong derivative biases")
+}
+
+func TestDenseLayerMatchesLayer(t *testing.T) {
+	rand.Seed(3)
+
+	// Sizes larger than gemmBlockSize so the tiled code paths are exercised
+	const batchSize int = 70
+	const inputCount int = 130
+	const layerSize int = 67
+
+	var l layer = newLayer(layerSize, inputCount)
+	var d denseLayer = newDenseLayerFromLayer(l)
+	var inputs matrix = randomMatrix(batchSize, inputCount)
+	var forwardDerivatives matrix = randomMatrix(batchSize, layerSize)
+
+	require.Equal(t, l.forward(inputs), d.forward(inputs), "Dense layer forward should be identical to layer forward")
+
+	l.backward(forwardDerivatives)
+	d.backward(forwardDerivatives)
+
+	require.Equal(t, l.getInputDerivatives(), d.getInputDerivatives(), "Dense layer input derivatives should be identical to layer input derivatives")
+
+	for neuronIndex, n := range l.neurons {
+		require.Equal(t, n.derivativeBias, d.derivativeBiases[neuronIndex], "Dense layer derivative bias should be identical to layer derivative bias")
+		// layer divides every product by the batch size before summing while denseLayer divides the sum once
+		require.InDeltaSlice(t, n.derivativeWeights, d.derivativeWeights.row(neuronIndex), 1e-12, "Dense layer derivative weights should match layer derivative weights")
+	}
+}
+
+func TestDenseLayerGradientCheck(t *testing.T) {
+	rand.Seed(5)
+	var l denseLayer = newDenseLayer(4, 3)
+
+	var report gradientCheckReport = checkComponentGradients(&l, randomMatrix(5, 3), defaultGradientCheckEpsilon)
+
+	require.Empty(t, report.failures(1e-6), report.String())
+}
+
+func TestDenseLayerParameters(t *testing.T) {
+	var d denseLayer = newDenseLayerExplicit(matrix{{1, 2}, {3, 4}}, vector{5, 6})
+	var l layer = newLayerExplicit(matrix{{1, 2}, {3, 4}}, vector{5, 6})
+
+	require.Equal(t, vector{1, 2, 5, 3, 4, 6}, d.getParameters(), "Parameters should be flattened neuron by neuron")
+	require.Equal(t, l.getParameters(), d.getParameters(), "Dense layer and layer should flatten parameters identically")
+	require.Equal(t, "neuron[1].bias", d.describeParameter(5), "Wrong parameter description")
+
+	d.setParameters(vector{7, 8, 9, 10, 11, 12})
+	require.Equal(t, matrix{{7, 8}, {10, 11}}, d.weights.toMatrix(), "setParameters should update weights")
+	require.Equal(t, vector{9, 12}, d.biases, "setParameters should update biases")
+	require.Panics(t, func() { d.setParameters(vector{1}) }, "Should panic with wrong parameter count")
+}
+
+const benchmarkBatchSize int = 256
+const benchmarkInputCount int = 128
+const benchmarkLayerSize int = 64
+
+func BenchmarkLayerForward(b *testing.B) {
+	var l layer = newLayer(benchmarkLayerSize, benchmarkInputCount)
+	var inputs matrix = randomMatrix(benchmarkBatchSize, benchmarkInputCount)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.forward(inputs)
+	}
+}
+
+func BenchmarkDenseLayerForward(b *testing.B) {
+	var l denseLayer = newDenseLayer(benchmarkLayerSize, benchmarkInputCount)
+	var inputs matrix = randomMatrix(benchmarkBatchSize, benchmarkInputCount)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.forward(inputs)
+	}
+}
+
+func BenchmarkLayerBackward(b *testing.B) {
+	var l layer = newLayer(benchmarkLayerSize, benchmarkInputCount)
+	var forwardDerivatives matrix = randomMatrix(benchmarkBatchSize, benchmarkLayerSize)
+	l.forward(randomMatrix(benchmarkBatchSize, benchmarkInputCount))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.backward(forwardDerivatives)
+		l.getInputDerivatives()
+	}
+}
+
+func BenchmarkDenseLayerBackward(b *testing.B) {
+	var l denseLayer = newDenseLayer(benchmarkLayerSize, benchmarkInputCount)
+	var forwardDerivatives matrix = randomMatrix(benchmarkBatchSize, benchmarkLayerSize)
+	l.forward(randomMatrix(benchmarkBatchSize, benchmarkInputCount))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.backward(forwardDerivatives)
+		l.getInputDerivatives()
+	}
+}
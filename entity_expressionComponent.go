@@ -0,0 +1,239 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+// tensorExpression computes a component's output from its input and parameters using tensor operations.
+type tensorExpression func(input *tensor, parameters []*tensor) *tensor
+
+// expressionComponent is a component defined only by its forward expression. Its backward pass is derived
+// by automatic differentiation. Parameter gradients are averaged over the batch to match layer.
+type expressionComponent struct {
+	expression         tensorExpression
+	parameters         []*tensor
+	lastInput          *tensor
+	lastOutput         *tensor
+	inputDerivatives   matrix
+	parameterGradients vector
+}
+
+func newExpressionComponent(expression tensorExpression, parameters ...*tensor) expressionComponent {
+	e, err := tryNewExpressionComponent(expression, parameters...)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return e
+}
+
+// tryNewExpressionComponent is newExpressionComponent returning an error for a missing expression instead of
+// panicking.
+func tryNewExpressionComponent(expression tensorExpression, parameters ...*tensor) (expressionComponent, error) {
+	if expression == nil {
+		return expressionComponent{}, errors.New("Can not create expression component without an expression")
+	}
+
+	return expressionComponent{expression: expression, parameters: parameters}, nil
+}
+
+// newDenseExpressionComponent is the automatically differentiated equivalent of newLayerExplicit.
+func newDenseExpressionComponent(weights matrix, biases vector) expressionComponent {
+	e, err := tryNewDenseExpressionComponent(weights, biases)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return e
+}
+
+// tryNewDenseExpressionComponent is newDenseExpressionComponent returning the errors of tryNewDenseLayerExplicit
+// instead of panicking.
+func tryNewDenseExpressionComponent(weights matrix, biases vector) (expressionComponent, error) {
+	l, err := tryNewDenseLayerExplicit(weights, biases)
+	if err != nil {
+		return expressionComponent{}, err
+	}
+
+	return tryNewExpressionComponent(denseExpression, newTensor(l.weights), newTensorFromRow(l.biases))
+}
+
+func (e *expressionComponent) forward(input matrix) matrix {
+	output, err := e.tryForward(input)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return output
+}
+
+// tryForward is forward returning an invalidDimensionError, a shapeMismatchError or a tensorShapeError instead of
+// panicking.
+func (e *expressionComponent) tryForward(input matrix) (matrix, error) {
+	inputTensor, output, err := e.evaluate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	e.lastInput = inputTensor
+	e.lastOutput = output
+	return output.value.toMatrix(), nil
+}
+
+func (e expressionComponent) predict(input matrix) matrix {
+	output, err := e.tryPredict(input)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return output
+}
+
+func (e expressionComponent) tryPredict(input matrix) (matrix, error) {
+	_, output, err := e.evaluate(input)
+	if err != nil {
+		return nil, err
+	}
+
+	return output.value.toMatrix(), nil
+}
+
+// evaluate runs the expression on input and returns the input and output tensors. Tensor operations panic with a
+// tensorShapeError on mismatching shapes, which is returned as an error; any other panic is a bug in the expression
+// and is raised again.
+func (e expressionComponent) evaluate(input matrix) (inputTensor *tensor, output *tensor, err error) {
+	if len(input) == 0 {
+		return nil, nil, newInvalidDimensionError("batch size", 0, "Can not forward expression component with empty input batch")
+	}
+
+	for _, inputRow := range input {
+		if len(inputRow) != len(input[0]) {
+			return nil, nil, newShapeMismatchError(len(input[0]), len(inputRow), "Can not forward expression component with rows of differing lengths %d and %d", len(input[0]), len(inputRow))
+		}
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			shapeErr, ok := recovered.(*tensorShapeError)
+			if !ok {
+				panic(recovered)
+			}
+
+			inputTensor, output, err = nil, nil, shapeErr
+		}
+	}()
+
+	inputTensor = newTensorFromMatrix(input)
+	output = e.expression(inputTensor, e.parameters)
+	if output == nil {
+		return nil, nil, newShapeMismatchError(len(input), 0, "Expression returned no output tensor for %d input rows", len(input))
+	}
+
+	if output.value.rows != len(input) {
+		return nil, nil, newShapeMismatchError(len(input), output.value.rows, "Expression output has %d rows but the input batch has %d", output.value.rows, len(input))
+	}
+
+	return inputTensor, output, nil
+}
+
+func (e expressionComponent) getInputDerivatives() matrix {
+	return e.inputDerivatives
+}
+
+func (e *expressionComponent) backward(forwardInputDerivatives matrix) {
+	if err := e.tryBackward(forwardInputDerivatives); err != nil {
+		panic(err.Error())
+	}
+}
+
+// tryBackward is backward returning a missingForwardStateError or shapeMismatchError instead of panicking.
+func (e *expressionComponent) tryBackward(forwardInputDerivatives matrix) error {
+	if e.lastOutput == nil {
+		return newMissingForwardStateError("expression component", "Expression component has no previous output. Can not back propigate")
+	}
+
+	if len(forwardInputDerivatives) != e.lastOutput.value.rows {
+		return newShapeMismatchError(e.lastOutput.value.rows, len(forwardInputDerivatives),
+			"Forward derivatives length %d does not match expression component last output length %d. There must be a row in the forward derivatives matrix for each output sample",
+			len(forwardInputDerivatives), e.lastOutput.value.rows,
+		)
+	}
+
+	for _, forwardDerivativeRow := range forwardInputDerivatives {
+		if len(forwardDerivativeRow) != e.lastOutput.value.cols {
+			return newShapeMismatchError(e.lastOutput.value.cols, len(forwardDerivativeRow),
+				"The passed forward input derivative containes a row whose length %d does not match the output row length %d",
+				len(forwardDerivativeRow), e.lastOutput.value.cols,
+			)
+		}
+	}
+
+	e.lastOutput.backwardWithGradient(denseMatrixFromMatrix(forwardInputDerivatives))
+	e.inputDerivatives = e.lastInput.gradient.toMatrix()
+
+	var batchSize float64 = float64(e.lastInput.value.rows)
+	e.parameterGradients = make(vector, 0, e.parameterCount())
+	for _, parameter := range e.parameters {
+		for _, gradientValue := range parameter.gradient.data {
+			e.parameterGradients = append(e.parameterGradients, gradientValue/batchSize)
+		}
+	}
+
+	return nil
+}
+
+func (e expressionComponent) parameterCount() int {
+	var count int = 0
+
+	for _, parameter := range e.parameters {
+		count += len(parameter.value.data)
+	}
+
+	return count
+}
+
+// getParameters flattens the parameter tensors in the order they were given, each row by row. A dense expression
+// component therefore lists all weights before all biases, unlike layer.
+func (e expressionComponent) getParameters() vector {
+	var parameters vector = make(vector, 0, e.parameterCount())
+
+	for _, parameter := range e.parameters {
+		parameters = append(parameters, parameter.value.data...)
+	}
+
+	return parameters
+}
+
+func (e *expressionComponent) setParameters(parameters vector) {
+	checkParameterCount(e, parameters)
+
+	var offset int = 0
+	for _, parameter := range e.parameters {
+		copy(parameter.value.data, parameters[offset:])
+		offset += len(parameter.value.data)
+	}
+}
+
+func (e expressionComponent) getParameterGradients() vector {
+	var gradients vector = make(vector, e.parameterCount())
+	copy(gradients, e.parameterGradients)
+	return gradients
+}
+
+func (e *expressionComponent) setParameterGradients(gradients vector) {
+	checkParameterCount(e, gradients)
+	e.parameterGradients = append(vector{}, gradients...)
+}
+
+func (e expressionComponent) describeParameter(index int) string {
+	for parameterIndex, parameter := range e.parameters {
+		if index < len(parameter.value.data) {
+			return fmt.Sprintf("parameter[%d][%d][%d]", parameterIndex, index/parameter.value.cols, index%parameter.value.cols)
+		}
+
+		index -= len(parameter.value.data)
+	}
+
+	panic(fmt.Sprintf("Parameter index %d is out of bounds of parameter count %d", index, e.parameterCount()))
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpressionComponentPanics(t *testing.T) {
+	var assert *assert.Assertions = assert.New(t)
+	var e expressionComponent = newExpressionComponent(reluExpression)
+
+	assert.Panics(func() { newExpressionComponent(nil) }, "Should panic without an expression")
+	assert.Panics(func() { e.backward(matrix{{1}}) }, "Should panic on back propigate with no previous output")
+
+	e.forward(matrix{{1, 1}, {1, 1}})
+	assert.Panics(func() { e.backward(matrix{{1, 1}}) }, "Should panic on back propigate when forward derivatives length does not match output length")
+	assert.Panics(func() { e.backward(matrix{{1, 1}, {1}}) }, "Should panic on back propigate when forward derivatives row length does not match output row length")
+}
+
+func TestExpressionComponentGradientCheck(t *testing.T) {
+	rand.Seed(2)
+	var e expressionComponent = newDenseExpressionComponent(randomMatrix(3, 4), vector{0.1, -0.2, 0.3})
+
+	var report gradientCheckReport = checkComponentGradients(&e, randomMatrix(5, 4), defaultGradientCheckEpsilon)
+
+	require.Len(t, report.entries, e.parameterCount()+20, "Gradient check should cover every parameter and input")
+	require.Empty(t, report.failures(1e-6), report.String())
+}
+
+func TestExpressionComponentParameters(t *testing.T) {
+	var e expressionComponent = newDenseExpressionComponent(matrix{{1, 2}, {3, 4}}, vector{5, 6})
+
+	require.Equal(t, vector{1, 2, 3, 4, 5, 6}, e.getParameters(), "Parameters should be flattened tensor by tensor")
+	require.Equal(t, "parameter[1][0][1]", e.describeParameter(5), "Wrong parameter description")
+
+	e.setParameters(vector{6, 5, 4, 3, 2, 1})
+	require.Equal(t, matrix{{6, 5}, {4, 3}}, e.parameters[0].value.toMatrix(), "setParameters should update the first tensor")
+	require.Equal(t, matrix{{2, 1}}, e.parameters[1].value.toMatrix(), "setParameters should update the second tensor")
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"math/rand"
 )
 
 type layer struct {
@@ -12,47 +13,86 @@ type layer struct {
 }
 
 func newLayer(layerSize, inputCount int) layer {
+	l, err := tryNewLayer(layerSize, inputCount)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return l
+}
+
+// tryNewLayer is newLayer returning an invalidDimensionError instead of panicking.
+func tryNewLayer(layerSize, inputCount int) (layer, error) {
+	return tryNewLayerWithRandom(layerSize, inputCount, nil)
+}
+
+// newLayerWithRandom is newLayer drawing the initial parameters from random instead of the global source.
+func newLayerWithRandom(layerSize, inputCount int, random *rand.Rand) layer {
+	l, err := tryNewLayerWithRandom(layerSize, inputCount, random)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return l
+}
+
+// tryNewLayerWithRandom is newLayerWithRandom returning an invalidDimensionError instead of panicking.
+func tryNewLayerWithRandom(layerSize, inputCount int, random *rand.Rand) (layer, error) {
 	if layerSize <= 0 {
-		panic(fmt.Sprintf("Can not create layer with size %d", layerSize))
+		return layer{}, newInvalidDimensionError("layer size", layerSize, "Can not create layer with size %d", layerSize)
 	}
 
 	if inputCount <= 0 {
-		panic(fmt.Sprintf("Can not create layer with input count %d", inputCount))
+		return layer{}, newInvalidDimensionError("input count", inputCount, "Can not create layer with input count %d", inputCount)
 	}
 
 	var neurons []neuron = make([]neuron, layerSize)
 	for index := range neurons {
-		neurons[index] = newNeuron(inputCount)
+		neurons[index] = newNeuronWithRandom(inputCount, random)
 	}
 
-	return layer{layerSize: layerSize, inputCount: inputCount, neurons: neurons}
+	return layer{layerSize: layerSize, inputCount: inputCount, neurons: neurons}, nil
 }
 
 func newLayerExplicit(weights matrix, biases vector) layer {
+	l, err := tryNewLayerExplicit(weights, biases)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return l
+}
+
+// tryNewLayerExplicit is newLayerExplicit returning an invalidDimensionError or shapeMismatchError instead of panicking.
+func tryNewLayerExplicit(weights matrix, biases vector) (layer, error) {
 	var neuronCount = len(weights)
 	var biasCount = len(biases)
 
 	if neuronCount == 0 {
-		panic("Can not create layer with 0 neurons")
+		return layer{}, newInvalidDimensionError("neuron count", neuronCount, "Can not create layer with 0 neurons")
 	}
 
 	if biasCount == 0 {
-		panic("Can not create layer with 0 biases")
+		return layer{}, newInvalidDimensionError("bias count", biasCount, "Can not create layer with 0 biases")
 	}
 
 	if neuronCount != biasCount {
-		panic(fmt.Sprintf("Layer neuron count %d does not match layer bias count %d", neuronCount, biasCount))
+		return layer{}, newShapeMismatchError(neuronCount, biasCount, "Layer neuron count %d does not match layer bias count %d", neuronCount, biasCount)
 	}
 
 	var firstWeightSetLen int = len(weights[0])
 	for index := range weights {
 		var currentWeightSet vector = weights[index]
 		if len(currentWeightSet) != firstWeightSetLen {
-			panic("Found neurons in layer with differint input counts")
+			return layer{}, newShapeMismatchError(firstWeightSetLen, len(currentWeightSet), "Found neurons in layer with differint input counts")
 		}
 	}
 
-	var l layer = newLayer(neuronCount, firstWeightSetLen)
+	l, err := tryNewLayer(neuronCount, firstWeightSetLen)
+	if err != nil {
+		return layer{}, err
+	}
+
 	l.layerSize = neuronCount
 	l.inputCount = firstWeightSetLen
 
@@ -62,7 +102,7 @@ func newLayerExplicit(weights matrix, biases vector) layer {
 		n.bias = biases[index]
 	}
 
-	return l
+	return l, nil
 }
 
 func (l layer) singleInputForward(input vector) vector {
@@ -86,95 +126,201 @@ func (l layer) singleInputForward(input vector) vector {
 }
 
 func (l *layer) forward(input matrix) matrix {
-	if len(input) == 0 {
-		panic("Can not forward layer with empty input batch")
+	output, err := l.tryForward(input)
+	if err != nil {
+		panic(err.Error())
 	}
 
-	var output matrix = make(matrix, len(input))
+	return output
+}
 
-	for rowIndex, inputSample := range input {
-		output[rowIndex] = l.singleInputForward(inputSample)
+// tryForward is forward returning an invalidDimensionError or shapeMismatchError instead of panicking.
+func (l *layer) tryForward(input matrix) (matrix, error) {
+	output, err := l.tryPredict(input)
+	if err != nil {
+		return nil, err
 	}
 
 	l.lastInput = input
+	return output, nil
+}
+
+func (l layer) predict(input matrix) matrix {
+	output, err := l.tryPredict(input)
+	if err != nil {
+		panic(err.Error())
+	}
+
 	return output
 }
 
+func (l layer) tryPredict(input matrix) (matrix, error) {
+	if len(input) == 0 {
+		return nil, newInvalidDimensionError("batch size", 0, "Can not forward layer with empty input batch")
+	}
+
+	for _, inputRow := range input {
+		if len(inputRow) != l.inputCount {
+			return nil, newShapeMismatchError(l.inputCount, len(inputRow), "Layer input count %d does not match len of provided input %d", l.inputCount, len(inputRow))
+		}
+	}
+
+	var output matrix = make(matrix, len(input))
+
+	computePool().run(len(input), func(start, end int) {
+		for rowIndex := start; rowIndex < end; rowIndex++ {
+			output[rowIndex] = l.singleInputForward(input[rowIndex])
+		}
+	})
+
+	return output, nil
+}
+
 // getLayerInputDerivatives pprocesses all the layers neurons input derivatives into a single matrix.
 func (l layer) getInputDerivatives() matrix {
 	var inputDerivatives matrix = make(matrix, len(l.lastInput))
 
-	for sampleIndex := range inputDerivatives {
-		var inputDerivativeForSample vector = make(vector, l.inputCount)
+	computePool().run(len(inputDerivatives), func(start, end int) {
+		for sampleIndex := start; sampleIndex < end; sampleIndex++ {
+			var inputDerivativeForSample vector = make(vector, l.inputCount)
 
-		for inputDerivativeIndex := range inputDerivativeForSample {
-			for _, n := range l.neurons {
-				inputDerivativeForSample[inputDerivativeIndex] = inputDerivativeForSample[inputDerivativeIndex] + n.derivativeInputs[sampleIndex][inputDerivativeIndex]
+			for inputDerivativeIndex := range inputDerivativeForSample {
+				for _, n := range l.neurons {
+					inputDerivativeForSample[inputDerivativeIndex] = inputDerivativeForSample[inputDerivativeIndex] + n.derivativeInputs[sampleIndex][inputDerivativeIndex]
+				}
 			}
-		}
 
-		inputDerivatives[sampleIndex] = inputDerivativeForSample
-	}
+			inputDerivatives[sampleIndex] = inputDerivativeForSample
+		}
+	})
 
 	return inputDerivatives
 }
 
 func (l *layer) backward(forwardInputDerivatives matrix) {
+	if err := l.tryBackward(forwardInputDerivatives); err != nil {
+		panic(err.Error())
+	}
+}
+
+// tryBackward is backward returning a missingForwardStateError or shapeMismatchError instead of panicking.
+func (l *layer) tryBackward(forwardInputDerivatives matrix) error {
 	var lastInputLen int = len(l.lastInput)
 	var forwardInputDerivativesLen int = len(forwardInputDerivatives)
 
 	if lastInputLen == 0 {
-		panic("Layer has not previous input. Can not backpropigate")
+		return newMissingForwardStateError("layer", "Layer has not previous input. Can not backpropigate")
 	}
 
 	if forwardInputDerivativesLen != lastInputLen {
-		panic(fmt.Sprintf(
+		return newShapeMismatchError(lastInputLen, forwardInputDerivativesLen,
 			"Forward derivatives length %d does not match previous inputs length %d. There must be a row in the forward derivatives matrix for each input sample in the previous input",
 			forwardInputDerivativesLen, lastInputLen,
-		))
+		)
 	}
 
 	for _, forwardDerivativeRow := range forwardInputDerivatives {
 		var forwardDerivativeRowLen int = len(forwardDerivativeRow)
 		if forwardDerivativeRowLen != l.layerSize {
-			panic(fmt.Sprintf("The passed forward input derivative contains a row whose length %d does not match the layer size %d", forwardDerivativeRowLen, l.layerSize))
+			return newShapeMismatchError(l.layerSize, forwardDerivativeRowLen, "The passed forward input derivative contains a row whose length %d does not match the layer size %d", forwardDerivativeRowLen, l.layerSize)
 		}
 	}
 
-	for neuronIndex := range l.neurons {
-		var n *neuron = &l.neurons[neuronIndex]
+	computePool().run(len(l.neurons), func(start, end int) {
+		for neuronIndex := start; neuronIndex < end; neuronIndex++ {
+			var n *neuron = &l.neurons[neuronIndex]
+
+			n.derivativeWeights = make(vector, len(n.weights))
+			for weightIndex := range n.derivativeWeights {
+				for inputSampleIndex, inputSample := range l.lastInput {
+					var forwardDerivativeForSample vector = forwardInputDerivatives[inputSampleIndex]
+					var forwardDerivativeValueForSample float64 = forwardDerivativeForSample[neuronIndex]
+					var inputValueForWeight float64 = inputSample[weightIndex]
+					var derivativeWeightForSample float64 = inputValueForWeight * forwardDerivativeValueForSample
+
+					derivativeWeightForSample /= float64(lastInputLen)
+					n.derivativeWeights[weightIndex] = n.derivativeWeights[weightIndex] + derivativeWeightForSample
+				}
+			}
 
-		n.derivativeWeights = make(vector, len(n.weights))
-		for weightIndex := range n.derivativeWeights {
-			for inputSampleIndex, inputSample := range l.lastInput {
-				var forwardDerivativeForSample vector = forwardInputDerivatives[inputSampleIndex]
-				var forwardDerivativeValueForSample float64 = forwardDerivativeForSample[neuronIndex]
-				var inputValueForWeight float64 = inputSample[weightIndex]
-				var derivativeWeightForSample float64 = inputValueForWeight * forwardDerivativeValueForSample
+			n.derivativeInputs = make(matrix, lastInputLen)
+			for inputSampleIndex := range l.lastInput {
+				var sampleDerivativeInput vector = make(vector, len(n.weights))
 
-				derivativeWeightForSample /= float64(lastInputLen)
-				n.derivativeWeights[weightIndex] = n.derivativeWeights[weightIndex] + derivativeWeightForSample
-			}
-		}
+				for derivativeIndex := range sampleDerivativeInput {
+					var matchingWeightValue float64 = n.weights[derivativeIndex]
+					var matchingForwardInputDerivative float64 = forwardInputDerivatives[inputSampleIndex][neuronIndex]
+					sampleDerivativeInput[derivativeIndex] = matchingWeightValue * matchingForwardInputDerivative
+				}
 
-		n.derivativeInputs = make(matrix, lastInputLen)
-		for inputSampleIndex := range l.lastInput {
-			var sampleDerivativeInput vector = make(vector, len(n.weights))
+				n.derivativeInputs[inputSampleIndex] = sampleDerivativeInput
+			}
 
-			for derivativeIndex := range sampleDerivativeInput {
-				var matchingWeightValue float64 = n.weights[derivativeIndex]
-				var matchingForwardInputDerivative float64 = forwardInputDerivatives[inputSampleIndex][neuronIndex]
-				sampleDerivativeInput[derivativeIndex] = matchingWeightValue * matchingForwardInputDerivative
+			n.derivativeBias = 0
+			for _, forwardDerivativeSample := range forwardInputDerivatives {
+				n.derivativeBias += (1 * forwardDerivativeSample[neuronIndex])
 			}
 
-			n.derivativeInputs[inputSampleIndex] = sampleDerivativeInput
+			n.derivativeBias = n.derivativeBias / float64(len(forwardInputDerivatives))
 		}
+	})
 
-		n.derivativeBias = 0
-		for _, forwardDerivativeSample := range forwardInputDerivatives {
-			n.derivativeBias += (1 * forwardDerivativeSample[neuronIndex])
-		}
+	return nil
+}
+
+func (l layer) parameterCount() int {
+	return l.layerSize * (l.inputCount + 1)
+}
+
+// getParameters flattens the parameters neuron by neuron, each neuron contributing its weights followed by its bias.
+func (l layer) getParameters() vector {
+	var parameters vector = make(vector, 0, l.parameterCount())
+
+	for _, n := range l.neurons {
+		parameters = append(parameters, n.weights...)
+		parameters = append(parameters, n.bias)
+	}
+
+	return parameters
+}
 
-		n.derivativeBias = n.derivativeBias / float64(len(forwardInputDerivatives))
+func (l *layer) setParameters(parameters vector) {
+	checkParameterCount(l, parameters)
+
+	for neuronIndex := range l.neurons {
+		var n *neuron = &l.neurons[neuronIndex]
+		var offset int = neuronIndex * (l.inputCount + 1)
+
+		copy(n.weights, parameters[offset:offset+l.inputCount])
+		n.bias = parameters[offset+l.inputCount]
+	}
+}
+
+func (l layer) getParameterGradients() vector {
+	var gradients vector = make(vector, l.parameterCount())
+
+	for neuronIndex, n := range l.neurons {
+		var offset int = neuronIndex * (l.inputCount + 1)
+
+		copy(gradients[offset:offset+l.inputCount], n.derivativeWeights)
+		gradients[offset+l.inputCount] = n.derivativeBias
 	}
+
+	return gradients
+}
+
+func (l *layer) setParameterGradients(gradients vector) {
+	checkParameterCount(l, gradients)
+
+	for neuronIndex := range l.neurons {
+		var n *neuron = &l.neurons[neuronIndex]
+		var offset int = neuronIndex * (l.inputCount + 1)
+
+		n.derivativeWeights = append(vector{}, gradients[offset:offset+l.inputCount]...)
+		n.derivativeBias = gradients[offset+l.inputCount]
+	}
+}
+
+func (l layer) describeParameter(index int) string {
+	return describeNeuronParameter(index, l.inputCount)
 }
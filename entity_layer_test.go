@@ -224,3 +224,20 @@ func TestLayerBackwardDerivativeWeights(t *testing.T) {
 	require.Equal(t, expectedNeuronDerivativeWeights[1], l.neurons[1].derivativeWeights, "Layer backwards produces wrong derivative weights for neuron 2")
 	require.Equal(t, expectedNeuronDerivativeWeights[2], l.neurons[2].derivativeWeights, "Layer backwards produces wrong derivative weights for neuron 3")
 }
+
+func TestLayerGradientCheck(t *testing.T) {
+	var l layer = newLayerExplicit(
+		matrix{{0.2, -0.5, 0.1}, {0.7, 0.3, -0.4}},
+		vector{0.1, -0.2},
+	)
+	var inputs matrix = matrix{
+		{1, -2, 0.5},
+		{0.3, 0.8, -1.2},
+		{-0.7, 0.1, 0.9},
+	}
+
+	var report gradientCheckReport = checkComponentGradients(&l, inputs, defaultGradientCheckEpsilon)
+
+	require.Len(t, report.entries, l.parameterCount()+9, "Gradient check should cover every parameter and input")
+	require.Empty(t, report.failures(1e-6), report.String())
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// logSoftmax outputs the logarithm of softmax. It is computed as input - max - log(sum(exp(input - max))) so it
+// stays finite where taking the log of a softmax output that underflowed to 0 would not. Pair it with
+// negativeLogLikelihood.
+type logSoftmax struct {
+	lastOutput       matrix
+	inputDerivatives matrix
+}
+
+func (s logSoftmax) singleInputForward(input vector) vector {
+	var output vector = make(vector, len(input))
+	var maxValue float64 = vectorMax(input)
+	if math.IsInf(maxValue, 0) {
+		for index, probability := range infiniteMaxSoftmax(input, maxValue) {
+			output[index] = math.Log(probability)
+		}
+
+		return output
+	}
+
+	var exponentialSum float64 = 0
+
+	for _, value := range input {
+		exponentialSum += math.Exp(value - maxValue)
+	}
+
+	var logSum float64 = math.Log(exponentialSum)
+	for index, value := range input {
+		output[index] = value - maxValue - logSum
+	}
+
+	return output
+}
+
+func (s *logSoftmax) forward(input matrix) matrix {
+	var output matrix = s.predict(input)
+	s.lastOutput = output
+	return output
+}
+
+func (s logSoftmax) predict(input matrix) matrix {
+	var output matrix = make(matrix, len(input))
+
+	computePool().run(len(input), func(start, end int) {
+		for inputRowIndex := start; inputRowIndex < end; inputRowIndex++ {
+			output[inputRowIndex] = s.singleInputForward(input[inputRowIndex])
+		}
+	})
+
+	return output
+}
+
+func (s logSoftmax) getInputDerivatives() matrix {
+	return s.inputDerivatives
+}
+
+// singleSampleBackward uses d output[j] / d input[i] = [i == j] - softmax[i], so the input derivative is the
+// forward derivative minus softmax times the sum of the forward derivatives.
+func (s logSoftmax) singleSampleBackward(forwardInputDerivativeRow vector, outputRow vector) vector {
+	var derivativeRowLen int = len(forwardInputDerivativeRow)
+	var outputRowLen int = len(outputRow)
+
+	if derivativeRowLen != outputRowLen {
+		panic(fmt.Sprintf(
+			"The passed forward input derivative containes a row whose length %d does not match the length %d of its corresponding output row",
+			derivativeRowLen, outputRowLen,
+		))
+	}
+
+	var forwardDerivativeSum float64 = vectorSum(forwardInputDerivativeRow)
+	var sampleInputDerivative vector = make(vector, outputRowLen)
+
+	for index, outputValue := range outputRow {
+		sampleInputDerivative[index] = forwardInputDerivativeRow[index] - math.Exp(outputValue)*forwardDerivativeSum
+	}
+
+	return sampleInputDerivative
+}
+
+func (s *logSoftmax) backward(forwardInputDerivatives matrix) {
+	if err := s.tryBackward(forwardInputDerivatives); err != nil {
+		panic(err.Error())
+	}
+}
+
+// tryBackward is backward returning a missingForwardStateError or shapeMismatchError instead of panicking.
+func (s *logSoftmax) tryBackward(forwardInputDerivatives matrix) error {
+	var lastOutputLen int = len(s.lastOutput)
+	var forwardDerivativesLen int = len(forwardInputDerivatives)
+
+	if lastOutputLen == 0 {
+		return newMissingForwardStateError("log softmax", "Log softmax has no previous output. Can not back propigate")
+	}
+
+	if forwardDerivativesLen != lastOutputLen {
+		return newShapeMismatchError(lastOutputLen, forwardDerivativesLen,
+			"Forward derivatives length %d does not match log softmax last output length %d. There must be a row in the forward derivatives matrix for each output sample",
+			forwardDerivativesLen, lastOutputLen,
+		)
+	}
+
+	for sampleIndex, forwardDerivativeRow := range forwardInputDerivatives {
+		var outputRowLen int = len(s.lastOutput[sampleIndex])
+
+		if len(forwardDerivativeRow) != outputRowLen {
+			return newShapeMismatchError(outputRowLen, len(forwardDerivativeRow),
+				"The passed forward input derivative containes a row whose length %d does not match the length %d of its corresponding output row",
+				len(forwardDerivativeRow), outputRowLen,
+			)
+		}
+	}
+
+	var inputDerivatives matrix = make(matrix, lastOutputLen)
+
+	computePool().run(len(inputDerivatives), func(start, end int) {
+		for sampleIndex := start; sampleIndex < end; sampleIndex++ {
+			inputDerivatives[sampleIndex] = s.singleSampleBackward(forwardInputDerivatives[sampleIndex], s.lastOutput[sampleIndex])
+		}
+	})
+
+	s.inputDerivatives = inputDerivatives
+	return nil
+}
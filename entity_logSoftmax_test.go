@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogSoftmaxForwardMatchesSoftmax(t *testing.T) {
+	var input matrix = matrix{
+		{2, 5, 6},
+		{4, 4, 6},
+	}
+
+	var s softmax
+	var l logSoftmax
+	var probabilities matrix = s.forward(input)
+	var logProbabilities matrix = l.forward(input)
+
+	for rowIndex, row := range probabilities {
+		for index, probability := range row {
+			assert.InDelta(t, math.Log(probability), logProbabilities[rowIndex][index], 1e-14, "Log softmax should be the log of softmax")
+		}
+	}
+}
+
+func TestLogSoftmaxForwardExtremeInputs(t *testing.T) {
+	var l logSoftmax
+	var output matrix = l.forward(matrix{
+		{1000, 0, -1000},
+		{-1000, -1000, -999},
+	})
+
+	assert.Equal(t, vector{0, -1000, -2000}, output[0], "Log softmax should stay finite where softmax underflows to 0")
+	assert.InDeltaSlice(t, vector{-1.5514447139320509, -1.5514447139320509, -0.5514447139320511}, output[1], 1e-12)
+}
+
+func TestLogSoftmaxForwardInfiniteInputs(t *testing.T) {
+	var l logSoftmax
+	var output matrix = l.forward(matrix{
+		{math.Inf(1), 0, math.Inf(1)},
+		{math.Inf(-1), math.Inf(-1)},
+	})
+
+	assert.Equal(t, vector{-math.Log(2), math.Inf(-1), -math.Log(2)}, output[0], "Infinite inputs should share the probability")
+	assert.Equal(t, vector{-math.Log(2), -math.Log(2)}, output[1], "A row of -Inf should be uniform")
+}
+
+func TestLogSoftmaxBackwardPanics(t *testing.T) {
+	var l logSoftmax
+	assert.Panics(t, func() { l.backward(matrix{{1, 1}}) }, "Should panic on back propigate when forward has not yet ben called")
+
+	l.forward(matrix{{1, 1}, {1, 1}})
+	assert.Panics(t, func() { l.backward(matrix{{1, 1}}) }, "Should panic when forward derivatives length does not match output length")
+	assert.Panics(t, func() { l.backward(matrix{{1, 1}, {1}}) }, "Should panic when forward derivatives row length does not match output row length")
+}
+
+func TestLogSoftmaxGradientCheck(t *testing.T) {
+	var inputs matrix = matrix{
+		{6, 2, 2},
+		{4, 3, 2},
+		{-1, 0.5, 0.25},
+	}
+
+	var l logSoftmax
+	var report gradientCheckReport = checkComponentGradients(&l, inputs, defaultGradientCheckEpsilon)
+
+	assert.Empty(t, report.failures(1e-6), report.String())
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+)
+
+// negativeLogLikelihood is the loss for log-probabilities, typically the output of logSoftmax. Together they compute
+// the same loss as softmax followed by crossentropy without clipping the probabilities.
+type negativeLogLikelihood struct {
+	lastInput        matrix
+	lastTargets      []int
+	lastOutput       vector
+	inputDerivatives matrix
+}
+
+func (n *negativeLogLikelihood) forward(input matrix, targets []int) vector {
+	output, err := n.tryForward(input, targets)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return output
+}
+
+// tryForward is forward returning a shapeMismatchError or targetOutOfRangeError instead of panicking.
+func (n *negativeLogLikelihood) tryForward(input matrix, targets []int) (vector, error) {
+	var inputLen int = len(input)
+	var targetsLen int = len(targets)
+
+	if inputLen != targetsLen {
+		return nil, newShapeMismatchError(inputLen, targetsLen,
+			"Negative log likelihood targets length %d does not match input batch size %d. There must be one target value per row in the inputs batch matrix",
+			targetsLen, inputLen,
+		)
+	}
+
+	var output vector = make(vector, inputLen)
+
+	for index, inputRow := range input {
+		var targetIndex int = targets[index]
+		var rowLength int = len(inputRow)
+
+		if targetIndex <= -1 || targetIndex >= rowLength {
+			return nil, &targetOutOfRangeError{
+				sample:     index,
+				target:     targetIndex,
+				classCount: rowLength,
+				message:    fmt.Sprintf("A negative log likelihood target index %d is out of bounds of its corresponding input row length %d", targetIndex, rowLength),
+			}
+		}
+
+		output[index] = -1 * inputRow[targetIndex]
+	}
+
+	n.lastInput = input
+	n.lastTargets = targets
+	n.lastOutput = output
+	return output, nil
+}
+
+func (n negativeLogLikelihood) getInputDerivatives() matrix {
+	return n.inputDerivatives
+}
+
+func (n *negativeLogLikelihood) backward() {
+	if len(n.lastInput) == 0 {
+		panic("Negative log likelihood has no previous input. Can not back propigate")
+	}
+
+	if len(n.lastTargets) == 0 {
+		panic("Negative log likelihood has no previous targets. Can not back propigate")
+	}
+
+	var inputDerivatives matrix = make(matrix, len(n.lastInput))
+	for inputDerivativeIndex, inputRow := range n.lastInput {
+		var derivativeRow vector = make(vector, len(inputRow))
+		derivativeRow[n.lastTargets[inputDerivativeIndex]] = -1
+		inputDerivatives[inputDerivativeIndex] = derivativeRow
+	}
+
+	n.inputDerivatives = inputDerivatives
+}
+
+func (n negativeLogLikelihood) calculateAverageLoss() float64 {
+	if len(n.lastOutput) == 0 {
+		panic("Negative log likelihood has not previous output. Can not calculate average loss")
+	}
+
+	return vectorSum(n.lastOutput) / float64(len(n.lastOutput))
+}
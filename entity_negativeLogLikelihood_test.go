@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegativeLogLikelihoodForward(t *testing.T) {
+	var n negativeLogLikelihood
+	var losses vector = n.forward(matrix{{-0.5, -1, -2}, {-3, -0.25, -1}}, []int{1, 2})
+
+	assert.Equal(t, vector{1, 1}, losses)
+	assert.Equal(t, 1.0, n.calculateAverageLoss())
+}
+
+func TestNegativeLogLikelihoodPanics(t *testing.T) {
+	var n negativeLogLikelihood
+	assert.Panics(t, func() { n.backward() }, "Should panic on back propigate when forward has not yet ben called")
+	assert.Panics(t, func() { n.calculateAverageLoss() }, "Should panic on average loss when forward has not yet ben called")
+	assert.PanicsWithValue(t,
+		"Negative log likelihood targets length 2 does not match input batch size 1. There must be one target value per row in the inputs batch matrix",
+		func() { n.forward(matrix{{-1, -1}}, []int{0, 1}) },
+		"Should panic when targets do not match the batch size",
+	)
+	assert.Panics(t, func() { n.forward(matrix{{-1, -1}}, []int{2}) }, "Should panic when a target is out of bounds")
+}
+
+func TestNegativeLogLikelihoodBackward(t *testing.T) {
+	var n negativeLogLikelihood
+	n.forward(matrix{{-0.5, -1, -2}, {-3, -0.25, -1}}, []int{1, 2})
+	n.backward()
+
+	assert.Equal(t, matrix{{0, -1, 0}, {0, 0, -1}}, n.getInputDerivatives())
+}
+
+func TestNegativeLogLikelihoodGradientCheck(t *testing.T) {
+	var n negativeLogLikelihood
+	var report gradientCheckReport = checkLossGradients(&n, matrix{{-0.5, -1, -2}, {-3, -0.25, -1}}, []int{1, 2}, defaultGradientCheckEpsilon)
+
+	assert.Empty(t, report.failures(1e-6), report.String())
+}
+
+func TestLogSoftmaxNegativeLogLikelihoodMatchesSoftmaxCrossentropy(t *testing.T) {
+	var inputs matrix = matrix{{6, 2, 2}, {4, 3, 2}, {-1, 0.5, 0.25}}
+	var targets []int = []int{0, 2, 1}
+
+	var s softmax
+	var c crossentropy
+	c.forward(s.forward(inputs), targets)
+	c.backward()
+	s.backward(c.getInputDerivatives())
+
+	var l logSoftmax
+	var n negativeLogLikelihood
+	n.forward(l.forward(inputs), targets)
+	n.backward()
+	l.backward(n.getInputDerivatives())
+
+	assert.InDelta(t, c.calculateAverageLoss(), n.calculateAverageLoss(), 1e-12)
+	requireMatrixInDelta(t, s.getInputDerivatives(), l.getInputDerivatives(), 1e-12, "Both pairs should produce the same input derivatives")
+}
+
+func TestLogSoftmaxNegativeLogLikelihoodExtremeInputs(t *testing.T) {
+	var l logSoftmax
+	var n negativeLogLikelihood
+	n.forward(l.forward(matrix{{1000, 0, -1000}}), []int{2})
+	n.backward()
+	l.backward(n.getInputDerivatives())
+
+	require.Equal(t, 2000.0, n.calculateAverageLoss(), "The loss should not be clipped or overflow")
+	assert.Equal(t, matrix{{1, 0, -1}}, l.getInputDerivatives())
+}
@@ -1,6 +1,9 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"math/rand"
+)
 
 type neuron struct {
 	weights           vector
@@ -11,14 +14,19 @@ type neuron struct {
 }
 
 func newNeuron(inputCount int) neuron {
+	return newNeuronWithRandom(inputCount, nil)
+}
+
+// newNeuronWithRandom is newNeuron drawing its initial parameters from random instead of the global source.
+func newNeuronWithRandom(inputCount int, random *rand.Rand) neuron {
 	if inputCount <= 0 {
 		panic(fmt.Sprintf("Can not create neuron with input count %d", inputCount))
 	}
 
-	var bias float64 = randRangeFloat64(0, 1)
+	var bias float64 = randRangeFloat64From(random, 0, 1)
 	var weights []float64 = make(vector, inputCount)
 	for index := range weights {
-		weights[index] = randRangeFloat64(0.1, 1)
+		weights[index] = randRangeFloat64From(random, 0.1, 1)
 	}
 
 	return neuron{weights: weights, bias: bias}
@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"math"
 )
 
@@ -11,6 +10,12 @@ type reluActivation struct {
 }
 
 func (r *reluActivation) forward(input matrix) matrix {
+	var output matrix = r.predict(input)
+	r.lastInput = input
+	return output
+}
+
+func (r reluActivation) predict(input matrix) matrix {
 	var output matrix = make(matrix, len(input))
 
 	for inputRowIndex, inputRow := range input {
@@ -21,7 +26,6 @@ func (r *reluActivation) forward(input matrix) matrix {
 		}
 	}
 
-	r.lastInput = input
 	return output
 }
 
@@ -30,18 +34,25 @@ func (r reluActivation) getInputDerivatives() matrix {
 }
 
 func (r *reluActivation) backward(forwardInputDerivatives matrix) {
+	if err := r.tryBackward(forwardInputDerivatives); err != nil {
+		panic(err.Error())
+	}
+}
+
+// tryBackward is backward returning a missingForwardStateError or shapeMismatchError instead of panicking.
+func (r *reluActivation) tryBackward(forwardInputDerivatives matrix) error {
 	var lastInputLen int = len(r.lastInput)
 	var forwardDerivativesLen int = len(forwardInputDerivatives)
 
 	if lastInputLen == 0 {
-		panic("RELU Activation has not previous input. Can not back propigate")
+		return newMissingForwardStateError("relu", "RELU Activation has not previous input. Can not back propigate")
 	}
 
 	if lastInputLen != forwardDerivativesLen {
-		panic(fmt.Sprintf(
+		return newShapeMismatchError(lastInputLen, forwardDerivativesLen,
 			"Forward derivatives length %d does not match previous input length %d. There must be a row in the forward derivatives matrix for each input sample in the previous input",
 			forwardDerivativesLen, lastInputLen,
-		))
+		)
 	}
 
 	for forwardDerivativeRowIndex := range forwardInputDerivatives {
@@ -49,10 +60,10 @@ func (r *reluActivation) backward(forwardInputDerivatives matrix) {
 		var inputRowLen int = len(r.lastInput[forwardDerivativeRowIndex])
 
 		if derivativeRowLen != inputRowLen {
-			panic(fmt.Sprintf(
+			return newShapeMismatchError(inputRowLen, derivativeRowLen,
 				"The passed forward input derivative containes a row whose length %d does not match the length %d of its corresponding input row",
 				derivativeRowLen, inputRowLen,
-			))
+			)
 		}
 	}
 
@@ -75,4 +86,5 @@ func (r *reluActivation) backward(forwardInputDerivatives matrix) {
 	}
 
 	r.inputDerivatives = inputDerivatives
+	return nil
 }
@@ -72,3 +72,16 @@ func TestReluBackwardDerivativeInput(t *testing.T) {
 
 	assert.Equal(t, expectedInputDerivatives, actualInputDerivatives, "RELU Activation back propigate produces wrong input derivatives")
 }
+
+func TestReluGradientCheck(t *testing.T) {
+	// Inputs are kept away from 0 where RELU is not differentiable
+	var inputs matrix = matrix{
+		{1.5, -0.5, 0.25},
+		{-2, 0.75, 3},
+	}
+
+	var r reluActivation = reluActivation{}
+	var report gradientCheckReport = checkComponentGradients(&r, inputs, defaultGradientCheckEpsilon)
+
+	assert.Empty(t, report.failures(1e-6), report.String())
+}
@@ -10,12 +10,19 @@ type softmax struct {
 	inputDerivatives matrix
 }
 
+// singleInputForward subtracts the largest input before exponentiating. This does not change the result but keeps
+// math.Exp from overflowing to +Inf on large inputs.
 func (s softmax) singleInputForward(input vector) vector {
+	var maxValue float64 = vectorMax(input)
+	if math.IsInf(maxValue, 0) {
+		return infiniteMaxSoftmax(input, maxValue)
+	}
+
 	var output vector = make(vector, len(input))
 	var exponentialSum float64 = 0
 
 	for index, value := range input {
-		var exponentialValue float64 = math.Exp(value)
+		var exponentialValue float64 = math.Exp(value - maxValue)
 		exponentialSum += exponentialValue
 		output[index] = exponentialValue
 	}
@@ -27,17 +34,44 @@ func (s softmax) singleInputForward(input vector) vector {
 	return output
 }
 
-func (s *softmax) forward(input matrix) matrix {
-	var output matrix = make(matrix, len(input))
+// infiniteMaxSoftmax is the limit of softmax for a row whose largest value is infinite, where subtracting it would
+// compute Inf - Inf = NaN: the probability is split evenly between the values equal to the maximum.
+func infiniteMaxSoftmax(input vector, maxValue float64) vector {
+	var maxCount int = 0
+	for _, value := range input {
+		if value == maxValue {
+			maxCount++
+		}
+	}
 
-	for inputRowIndex, inputRow := range input {
-		output[inputRowIndex] = s.singleInputForward(inputRow)
+	var output vector = make(vector, len(input))
+	for index, value := range input {
+		if value == maxValue {
+			output[index] = 1 / float64(maxCount)
+		}
 	}
 
+	return output
+}
+
+func (s *softmax) forward(input matrix) matrix {
+	var output matrix = s.predict(input)
 	s.lastOutput = output
 	return output
 }
 
+func (s softmax) predict(input matrix) matrix {
+	var output matrix = make(matrix, len(input))
+
+	computePool().run(len(input), func(start, end int) {
+		for inputRowIndex := start; inputRowIndex < end; inputRowIndex++ {
+			output[inputRowIndex] = s.singleInputForward(input[inputRowIndex])
+		}
+	})
+
+	return output
+}
+
 func (s softmax) getInputDerivatives() matrix {
 	return s.inputDerivatives
 }
@@ -83,29 +117,50 @@ func (s softmax) singleSampleBackward(forwardInputDerivativeRow vector, outputRo
 }
 
 func (s *softmax) backward(forwardInputDerivatives matrix) {
+	if err := s.tryBackward(forwardInputDerivatives); err != nil {
+		panic(err.Error())
+	}
+}
+
+// tryBackward is backward returning a missingForwardStateError or shapeMismatchError instead of panicking.
+func (s *softmax) tryBackward(forwardInputDerivatives matrix) error {
 	var lastOutputLen int = len(s.lastOutput)
 	var forwardDerivativesLen int = len(forwardInputDerivatives)
 
 	if lastOutputLen == 0 {
-		panic("Softmax has no previous output. Can not back propigate")
+		return newMissingForwardStateError("softmax", "Softmax has no previous output. Can not back propigate")
 	}
 
 	if forwardDerivativesLen != lastOutputLen {
-		panic(fmt.Sprintf(
+		return newShapeMismatchError(lastOutputLen, forwardDerivativesLen,
 			"Forward derivatives length %d does not match softmax last output length %d. There must be a row in the forward derivatives matrix for each output sample",
 			forwardDerivativesLen, lastOutputLen,
-		))
+		)
+	}
+
+	for sampleIndex, forwardDerivativeRow := range forwardInputDerivatives {
+		var outputRowLen int = len(s.lastOutput[sampleIndex])
+
+		if len(forwardDerivativeRow) != outputRowLen {
+			return newShapeMismatchError(outputRowLen, len(forwardDerivativeRow),
+				"The passed forward input derivative containes a row whose length %d does not match the length %d of its corresponding output row",
+				len(forwardDerivativeRow), outputRowLen,
+			)
+		}
 	}
 
 	var inputDerivatives matrix = make(matrix, lastOutputLen)
 
-	for sampleIndex := range inputDerivatives {
-		var sampleOutput vector = s.lastOutput[sampleIndex]
-		var sampleForwardDerivative vector = forwardInputDerivatives[sampleIndex]
-		var sampleInputDerivative vector = s.singleSampleBackward(sampleForwardDerivative, sampleOutput)
+	computePool().run(len(inputDerivatives), func(start, end int) {
+		for sampleIndex := start; sampleIndex < end; sampleIndex++ {
+			var sampleOutput vector = s.lastOutput[sampleIndex]
+			var sampleForwardDerivative vector = forwardInputDerivatives[sampleIndex]
+			var sampleInputDerivative vector = s.singleSampleBackward(sampleForwardDerivative, sampleOutput)
 
-		inputDerivatives[sampleIndex] = sampleInputDerivative
-	}
+			inputDerivatives[sampleIndex] = sampleInputDerivative
+		}
+	})
 
 	s.inputDerivatives = inputDerivatives
+	return nil
 }
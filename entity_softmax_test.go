@@ -1,6 +1,7 @@
 package main
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -13,8 +14,8 @@ func TestSoftmaxForward(t *testing.T) {
 	}
 
 	var expectedOutput matrix = matrix{
-		{0.013212886953789417, 0.265387928772242, 0.7213991842739688},
-		{0.10650697891920076, 0.10650697891920076, 0.7869860421615986},
+		{0.013212886953789414, 0.26538792877224193, 0.7213991842739687},
+		{0.10650697891920076, 0.10650697891920076, 0.7869860421615985},
 	}
 
 	var s softmax
@@ -23,6 +24,34 @@ func TestSoftmaxForward(t *testing.T) {
 	assert.Equal(t, actualOutput, expectedOutput, "Softmax forward returns wrong value")
 }
 
+func TestSoftmaxForwardExtremeInputs(t *testing.T) {
+	var input matrix = matrix{
+		{1000, 0, -1000},
+		{1000, 1000, 1000},
+		{-1000, -1000, -999},
+	}
+
+	var s softmax
+	var output matrix = s.forward(input)
+
+	assert.Equal(t, vector{1, 0, 0}, output[0], "Softmax should not overflow on large inputs")
+	assert.InDeltaSlice(t, vector{1.0 / 3, 1.0 / 3, 1.0 / 3}, output[1], 1e-15, "Softmax should not overflow on equal large inputs")
+	assert.InDeltaSlice(t, vector{0.21194155761708547, 0.21194155761708547, 0.5761168847658291}, output[2], 1e-15, "Softmax should not underflow on large negative inputs")
+}
+
+func TestSoftmaxForwardInfiniteInputs(t *testing.T) {
+	var input matrix = matrix{
+		{math.Inf(1), 0, math.Inf(1)},
+		{math.Inf(-1), math.Inf(-1)},
+	}
+
+	var s softmax
+	var output matrix = s.forward(input)
+
+	assert.Equal(t, vector{0.5, 0, 0.5}, output[0], "Infinite inputs should share the probability instead of turning into NaN")
+	assert.Equal(t, vector{0.5, 0.5}, output[1], "A row of -Inf should be uniform")
+}
+
 func TestSoftmaxBackwardPanics(t *testing.T) {
 	var assert *assert.Assertions = assert.New(t)
 	var s softmax
@@ -62,8 +91,8 @@ func TestSoftmaxBackwardDerivativeInput(t *testing.T) {
 	s.backward(mockForwardInputDerivatives)
 
 	var expectedInputDerivatives matrix = matrix{
-		{0.034088151482230225, -0.017044075741115054, -0.017044075741115054},
-		{-0.10291137744498538, 0.20686949103015304, -0.1039581135851675},
+		{0.034088151482230225, -0.01704407574111505, -0.01704407574111505},
+		{-0.10291137744498538, 0.20686949103015304, -0.10395811358516752},
 	}
 
 	var actualInputDerivatives matrix = s.getInputDerivatives()
@@ -71,3 +100,16 @@ func TestSoftmaxBackwardDerivativeInput(t *testing.T) {
 	assert.Equal(t, expectedInputDerivatives, actualInputDerivatives, "Softmax backwards produces wrong input derivatives")
 
 }
+
+func TestSoftmaxGradientCheck(t *testing.T) {
+	var inputs matrix = matrix{
+		{6, 2, 2},
+		{4, 3, 2},
+		{-1, 0.5, 0.25},
+	}
+
+	var s softmax = softmax{}
+	var report gradientCheckReport = checkComponentGradients(&s, inputs, defaultGradientCheckEpsilon)
+
+	assert.Empty(t, report.failures(1e-6), report.String())
+}
@@ -0,0 +1,85 @@
+package main
+
+import "fmt"
+
+// shapeMismatchError is returned when a matrix, vector or target list does not have the length a component
+// expects. expected and actual are the mismatching lengths.
+type shapeMismatchError struct {
+	expected int
+	actual   int
+	message  string
+}
+
+func newShapeMismatchError(expected, actual int, format string, args ...interface{}) *shapeMismatchError {
+	return &shapeMismatchError{expected: expected, actual: actual, message: fmt.Sprintf(format, args...)}
+}
+
+func (e *shapeMismatchError) Error() string {
+	return e.message
+}
+
+// invalidDimensionError is returned when a size, count or batch that has to be positive is not.
+type invalidDimensionError struct {
+	dimension string
+	value     int
+	message   string
+}
+
+func newInvalidDimensionError(dimension string, value int, format string, args ...interface{}) *invalidDimensionError {
+	return &invalidDimensionError{dimension: dimension, value: value, message: fmt.Sprintf(format, args...)}
+}
+
+func (e *invalidDimensionError) Error() string {
+	return e.message
+}
+
+// missingForwardStateError is returned when a component is propagated backward before it was propagated forward.
+type missingForwardStateError struct {
+	component string
+	message   string
+}
+
+func newMissingForwardStateError(component, message string) *missingForwardStateError {
+	return &missingForwardStateError{component: component, message: message}
+}
+
+func (e *missingForwardStateError) Error() string {
+	return e.message
+}
+
+// tensorShapeError is raised as a panic by a tensor operation whose operands have incompatible shapes.
+// expressionComponent returns it as an error; tensor code outside a component panics with it like with a string.
+type tensorShapeError struct {
+	operation string
+	message   string
+}
+
+func newTensorShapeError(operation, format string, args ...interface{}) *tensorShapeError {
+	return &tensorShapeError{operation: operation, message: fmt.Sprintf(format, args...)}
+}
+
+func (e *tensorShapeError) Error() string {
+	return e.message
+}
+
+// targetOutOfRangeError is returned when a target class index does not exist in the row it refers to.
+type targetOutOfRangeError struct {
+	sample     int
+	target     int
+	classCount int
+	message    string
+}
+
+func (e *targetOutOfRangeError) Error() string {
+	return e.message
+}
+
+// unknownCategoryError is returned when an encoder meets a category it was not fitted on.
+type unknownCategoryError struct {
+	category string
+	message  string
+}
+
+func (e *unknownCategoryError) Error() string {
+	return e.message
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireShapeMismatch(t *testing.T, err error, expected, actual int) {
+	var shapeErr *shapeMismatchError
+	require.True(t, errors.As(err, &shapeErr), "Should fail with a shapeMismatchError, got %v", err)
+	assert.Equal(t, expected, shapeErr.expected)
+	assert.Equal(t, actual, shapeErr.actual)
+}
+
+func requireMissingForwardState(t *testing.T, err error, component string) {
+	var stateErr *missingForwardStateError
+	require.True(t, errors.As(err, &stateErr), "Should fail with a missingForwardStateError, got %v", err)
+	assert.Equal(t, component, stateErr.component)
+}
+
+func TestTryNewLayerErrors(t *testing.T) {
+	var dimensionErr *invalidDimensionError
+
+	_, err := tryNewLayer(0, 3)
+	require.True(t, errors.As(err, &dimensionErr))
+	assert.Equal(t, "layer size", dimensionErr.dimension)
+
+	_, err = tryNewLayer(2, -1)
+	require.True(t, errors.As(err, &dimensionErr))
+	assert.Equal(t, "input count", dimensionErr.dimension)
+	assert.Equal(t, -1, dimensionErr.value)
+
+	_, err = tryNewLayerExplicit(matrix{{1, 2}, {3, 4}}, vector{1})
+	requireShapeMismatch(t, err, 2, 1)
+
+	_, err = tryNewLayerExplicit(matrix{{1, 2}, {3}}, vector{1, 2})
+	requireShapeMismatch(t, err, 2, 1)
+
+	l, err := tryNewLayerExplicit(matrix{{1, 2}}, vector{3})
+	require.NoError(t, err)
+	assert.Equal(t, 1, l.layerSize)
+}
+
+func TestLayerTryForwardAndBackwardErrors(t *testing.T) {
+	var l layer = newLayerExplicit(matrix{{1, 2}, {3, 4}}, vector{0, 0})
+
+	requireMissingForwardState(t, l.tryBackward(matrix{{1, 1}}), "layer")
+
+	_, err := l.tryForward(matrix{})
+	var dimensionErr *invalidDimensionError
+	require.True(t, errors.As(err, &dimensionErr))
+
+	_, err = l.tryForward(matrix{{1, 2}, {1, 2, 3}})
+	requireShapeMismatch(t, err, 2, 3)
+	assert.Nil(t, l.lastInput, "A failed forward should not store its input")
+
+	output, err := l.tryForward(matrix{{1, 1}})
+	require.NoError(t, err)
+	assert.Equal(t, matrix{{3, 7}}, output)
+
+	requireShapeMismatch(t, l.tryBackward(matrix{{1, 1}, {1, 1}}), 1, 2)
+	requireShapeMismatch(t, l.tryBackward(matrix{{1, 1, 1}}), 2, 3)
+	require.NoError(t, l.tryBackward(matrix{{1, 1}}))
+}
+
+func TestDenseLayerTryErrors(t *testing.T) {
+	var dimensionErr *invalidDimensionError
+
+	_, err := tryNewDenseLayer(0, 3)
+	require.True(t, errors.As(err, &dimensionErr))
+	assert.Equal(t, "layer size", dimensionErr.dimension)
+
+	_, err = tryNewDenseLayerExplicit(matrix{{1, 2}, {3, 4}}, vector{1})
+	requireShapeMismatch(t, err, 2, 1)
+
+	l, err := tryNewDenseLayerExplicit(matrix{{1, 2}, {3, 4}}, vector{0, 0})
+	require.NoError(t, err)
+
+	requireMissingForwardState(t, l.tryBackward(matrix{{1, 1}}), "dense layer")
+
+	_, err = l.tryForward(matrix{{1, 2, 3}})
+	requireShapeMismatch(t, err, 2, 3)
+
+	output, err := l.tryForward(matrix{{1, 1}})
+	require.NoError(t, err)
+	assert.Equal(t, matrix{{3, 7}}, output)
+
+	requireShapeMismatch(t, l.tryBackward(matrix{{1, 1}, {1, 1}}), 1, 2)
+	requireShapeMismatch(t, l.tryBackward(matrix{{1, 1, 1}}), 2, 3)
+	require.NoError(t, l.tryBackward(matrix{{1, 1}}))
+}
+
+func TestExpressionComponentTryErrors(t *testing.T) {
+	_, err := tryNewExpressionComponent(nil)
+	require.Error(t, err)
+
+	e, err := tryNewDenseExpressionComponent(matrix{{1, 2}}, vector{0})
+	require.NoError(t, err)
+
+	requireMissingForwardState(t, e.tryBackward(matrix{{1}}), "expression component")
+
+	_, err = e.tryForward(matrix{{1, 2}, {1}})
+	requireShapeMismatch(t, err, 2, 1)
+
+	_, err = e.tryForward(matrix{{1, 2, 3}})
+	var tensorShapeErr *tensorShapeError
+	require.True(t, errors.As(err, &tensorShapeErr), "A shape mismatch inside the expression should be returned as a tensorShapeError")
+	assert.Equal(t, "matMul", tensorShapeErr.operation)
+	assert.Nil(t, e.lastOutput, "A failed forward should not store its output")
+
+	var buggy expressionComponent = newExpressionComponent(func(input *tensor, parameters []*tensor) *tensor {
+		return parameters[0]
+	})
+	assert.Panics(t, func() { buggy.tryForward(matrix{{1}}) }, "A runtime error inside the expression should not be hidden as an error")
+
+	var reshaping expressionComponent = newExpressionComponent(func(input *tensor, parameters []*tensor) *tensor {
+		return input.sum()
+	})
+	_, err = reshaping.tryForward(matrix{{1}, {2}})
+	requireShapeMismatch(t, err, 2, 1)
+
+	_, err = e.tryForward(matrix{{1, 1}})
+	require.NoError(t, err)
+	requireShapeMismatch(t, e.tryBackward(matrix{{1}, {1}}), 1, 2)
+	require.NoError(t, e.tryBackward(matrix{{1}}))
+}
+
+func TestFeatureScalerTryErrors(t *testing.T) {
+	_, err := tryNewFeatureScaler("log")
+	require.Error(t, err)
+
+	_, err = tryNewFeatureScalerExplicit(scalerKindStandard, vector{0, 0}, vector{1})
+	requireShapeMismatch(t, err, 2, 1)
+
+	for _, scales := range []vector{{0}, {math.NaN()}, {math.Inf(1)}} {
+		_, err = tryNewFeatureScalerExplicit(scalerKindStandard, vector{0}, scales)
+		require.Error(t, err, "Scale %v should be rejected", scales[0])
+	}
+	_, err = tryNewFeatureScalerExplicit(scalerKindStandard, vector{math.Inf(-1)}, vector{1})
+	require.Error(t, err, "Non-finite centers should be rejected")
+
+	var s *featureScaler = newFeatureScaler(scalerKindStandard)
+	_, err = s.tryTransform(matrix{{1}})
+	require.Error(t, err, "Transforming with an unfitted scaler should fail")
+
+	requireShapeMismatch(t, s.tryFit(matrix{{1, 2}, {1}}), 2, 1)
+	require.NoError(t, s.tryFit(matrix{{1, 2}, {3, 4}}))
+
+	_, err = s.tryTransform(matrix{{1}})
+	requireShapeMismatch(t, err, 2, 1)
+	requireShapeMismatch(t, s.tryBackward(matrix{{1, 1, 1}}), 2, 3)
+}
+
+func TestActivationTryBackwardErrors(t *testing.T) {
+	var r reluActivation
+	requireMissingForwardState(t, r.tryBackward(matrix{{1}}), "relu")
+	r.forward(matrix{{1, -1}})
+	requireShapeMismatch(t, r.tryBackward(matrix{{1, 1}, {1, 1}}), 1, 2)
+	requireShapeMismatch(t, r.tryBackward(matrix{{1}}), 2, 1)
+	require.NoError(t, r.tryBackward(matrix{{1, 1}}))
+
+	var s softmax
+	requireMissingForwardState(t, s.tryBackward(matrix{{1}}), "softmax")
+	s.forward(matrix{{1, -1}})
+	requireShapeMismatch(t, s.tryBackward(matrix{{1, 1}, {1, 1}}), 1, 2)
+	requireShapeMismatch(t, s.tryBackward(matrix{{1, 1, 1}}), 2, 3)
+	require.NoError(t, s.tryBackward(matrix{{1, 1}}))
+
+	var l logSoftmax
+	requireMissingForwardState(t, l.tryBackward(matrix{{1}}), "log softmax")
+	l.forward(matrix{{1, -1}})
+	requireShapeMismatch(t, l.tryBackward(matrix{{1, 1}, {1, 1}}), 1, 2)
+	requireShapeMismatch(t, l.tryBackward(matrix{{1, 1, 1}}), 2, 3)
+	require.NoError(t, l.tryBackward(matrix{{1, 1}}))
+}
+
+func TestCrossentropyTryForwardErrors(t *testing.T) {
+	var c crossentropy
+
+	_, err := c.tryForward(matrix{{0.5, 0.5}}, []int{0, 1})
+	requireShapeMismatch(t, err, 1, 2)
+
+	_, err = c.tryForward(matrix{{0.5, 0.5}, {0.5, 0.5}}, []int{0, 2})
+	var targetErr *targetOutOfRangeError
+	require.True(t, errors.As(err, &targetErr))
+	assert.Equal(t, 1, targetErr.sample)
+	assert.Equal(t, 2, targetErr.target)
+	assert.Equal(t, 2, targetErr.classCount)
+
+	losses, err := c.tryForward(matrix{{0.5, 0.5}}, []int{0})
+	require.NoError(t, err)
+	assert.Len(t, losses, 1)
+}
+
+func TestNegativeLogLikelihoodTryForwardErrors(t *testing.T) {
+	var n negativeLogLikelihood
+
+	_, err := n.tryForward(matrix{{-1, -1}}, []int{0, 1})
+	requireShapeMismatch(t, err, 1, 2)
+
+	_, err = n.tryForward(matrix{{-1, -1}}, []int{2})
+	var targetErr *targetOutOfRangeError
+	require.True(t, errors.As(err, &targetErr))
+	assert.Equal(t, 2, targetErr.target)
+
+	losses, err := n.tryForward(matrix{{-1, -2}}, []int{1})
+	require.NoError(t, err)
+	assert.Equal(t, vector{2}, losses)
+}
+
+func TestPanickingWrappersKeepMessages(t *testing.T) {
+	assert.PanicsWithValue(t, "Can not create layer with size 0", func() { newLayer(0, 1) })
+	assert.PanicsWithValue(t, "Layer has not previous input. Can not backpropigate", func() {
+		var l layer = newLayer(1, 1)
+		l.backward(matrix{{1}})
+	})
+	assert.PanicsWithValue(t, "A crossentropy target index 3 is out of bounds of its corresponding input row length 2", func() {
+		var c crossentropy
+		c.forward(matrix{{0.5, 0.5}}, []int{3})
+	})
+}
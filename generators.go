@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// The generators return samples grouped by class, in the same order for the same seed. Noise is normal with the
+// given standard deviation.
+
+func checkGeneratorCounts(samples, classes int) {
+	if samples <= 0 {
+		panic(fmt.Sprintf("Can not generate %d samples", samples))
+	}
+
+	if classes <= 0 {
+		panic(fmt.Sprintf("Can not generate %d classes", classes))
+	}
+}
+
+// generateSpiral generates the spiral dataset of "Neural Networks from Scratch": classes arms of samplesPerClass
+// two-dimensional points winding out from the origin.
+func generateSpiral(samplesPerClass, classes int, noise float64, seed int64) (matrix, []int) {
+	checkGeneratorCounts(samplesPerClass, classes)
+
+	var random *rand.Rand = rand.New(newSplitMix64Source(seed))
+	var inputs matrix = make(matrix, 0, samplesPerClass*classes)
+	var targets []int = make([]int, 0, samplesPerClass*classes)
+
+	for class := 0; class < classes; class++ {
+		for index := 0; index < samplesPerClass; index++ {
+			var progress float64 = 0
+			if samplesPerClass > 1 {
+				progress = float64(index) / float64(samplesPerClass-1)
+			}
+
+			var radius float64 = progress
+			var angle float64 = (float64(class)+progress)*4 + random.NormFloat64()*noise
+			inputs = append(inputs, vector{radius * math.Sin(angle*2.5), radius * math.Cos(angle*2.5)})
+			targets = append(targets, class)
+		}
+	}
+
+	return inputs, targets
+}
+
+// generateBlobs generates a gaussian blob of samplesPerClass points with standard deviation spread around a random
+// center in [-10, 10] for every class.
+func generateBlobs(samplesPerClass, classes, features int, spread float64, seed int64) (matrix, []int) {
+	checkGeneratorCounts(samplesPerClass, classes)
+	if features <= 0 {
+		panic(fmt.Sprintf("Can not generate %d features", features))
+	}
+
+	var random *rand.Rand = rand.New(newSplitMix64Source(seed))
+	var centers matrix = make(matrix, classes)
+	for class := range centers {
+		centers[class] = make(vector, features)
+		for feature := range centers[class] {
+			centers[class][feature] = random.Float64()*20 - 10
+		}
+	}
+
+	var inputs matrix = make(matrix, 0, samplesPerClass*classes)
+	var targets []int = make([]int, 0, samplesPerClass*classes)
+
+	for class, center := range centers {
+		for index := 0; index < samplesPerClass; index++ {
+			var point vector = make(vector, features)
+			for feature := range point {
+				point[feature] = center[feature] + random.NormFloat64()*spread
+			}
+
+			inputs = append(inputs, point)
+			targets = append(targets, class)
+		}
+	}
+
+	return inputs, targets
+}
+
+// generateMoons generates two interleaving half circles: class 0 is the upper half of the unit circle and class 1
+// the lower half shifted by (1, 0.5).
+func generateMoons(samplesPerClass int, noise float64, seed int64) (matrix, []int) {
+	checkGeneratorCounts(samplesPerClass, 2)
+
+	var random *rand.Rand = rand.New(newSplitMix64Source(seed))
+	var inputs matrix = make(matrix, 0, samplesPerClass*2)
+	var targets []int = make([]int, 0, samplesPerClass*2)
+
+	for class := 0; class < 2; class++ {
+		for index := 0; index < samplesPerClass; index++ {
+			var angle float64 = 0
+			if samplesPerClass > 1 {
+				angle = math.Pi * float64(index) / float64(samplesPerClass-1)
+			}
+
+			var point vector = vector{math.Cos(angle), math.Sin(angle)}
+			if class == 1 {
+				point = vector{1 - math.Cos(angle), 0.5 - math.Sin(angle)}
+			}
+
+			point[0] += random.NormFloat64() * noise
+			point[1] += random.NormFloat64() * noise
+			inputs = append(inputs, point)
+			targets = append(targets, class)
+		}
+	}
+
+	return inputs, targets
+}
+
+// generateCircles generates two concentric circles: class 0 on the unit circle and class 1 on a circle of radius
+// factor, which has to be in (0, 1).
+func generateCircles(samplesPerClass int, factor, noise float64, seed int64) (matrix, []int) {
+	checkGeneratorCounts(samplesPerClass, 2)
+	if factor <= 0 || factor >= 1 {
+		panic(fmt.Sprintf("Can not generate circles with factor %f. Factor must be in (0, 1)", factor))
+	}
+
+	var random *rand.Rand = rand.New(newSplitMix64Source(seed))
+	var inputs matrix = make(matrix, 0, samplesPerClass*2)
+	var targets []int = make([]int, 0, samplesPerClass*2)
+
+	for class, radius := range []float64{1, factor} {
+		for index := 0; index < samplesPerClass; index++ {
+			var angle float64 = 2 * math.Pi * float64(index) / float64(samplesPerClass)
+
+			inputs = append(inputs, vector{
+				radius*math.Cos(angle) + random.NormFloat64()*noise,
+				radius*math.Sin(angle) + random.NormFloat64()*noise,
+			})
+			targets = append(targets, class)
+		}
+	}
+
+	return inputs, targets
+}
+
+// generateSine generates the regression dataset of "Neural Networks from Scratch": samples evenly spaced inputs x
+// in [0, 1] with the target sin(2πx) plus noise.
+func generateSine(samples int, noise float64, seed int64) (matrix, matrix) {
+	checkGeneratorCounts(samples, 1)
+
+	var random *rand.Rand = rand.New(newSplitMix64Source(seed))
+	var inputs matrix = make(matrix, samples)
+	var targets matrix = make(matrix, samples)
+
+	for index := range inputs {
+		var x float64 = 0
+		if samples > 1 {
+			x = float64(index) / float64(samples-1)
+		}
+
+		inputs[index] = vector{x}
+		targets[index] = vector{math.Sin(2*math.Pi*x) + random.NormFloat64()*noise}
+	}
+
+	return inputs, targets
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireClassCounts(t *testing.T, targets []int, classes, samplesPerClass int) {
+	var counts []int = make([]int, classes)
+	for _, target := range targets {
+		require.True(t, target >= 0 && target < classes, "Target %d is out of range", target)
+		counts[target]++
+	}
+
+	for class, count := range counts {
+		require.Equal(t, samplesPerClass, count, "class %d", class)
+	}
+}
+
+func TestGeneratorsAreSeeded(t *testing.T) {
+	var generators map[string]func(seed int64) (matrix, interface{}) = map[string]func(seed int64) (matrix, interface{}){
+		"spiral":  func(seed int64) (matrix, interface{}) { return generateSpiral(20, 3, 0.2, seed) },
+		"blobs":   func(seed int64) (matrix, interface{}) { return generateBlobs(20, 3, 2, 1, seed) },
+		"moons":   func(seed int64) (matrix, interface{}) { return generateMoons(20, 0.1, seed) },
+		"circles": func(seed int64) (matrix, interface{}) { return generateCircles(20, 0.5, 0.05, seed) },
+		"sine":    func(seed int64) (matrix, interface{}) { return generateSine(20, 0.1, seed) },
+	}
+
+	for name, generate := range generators {
+		firstInputs, firstTargets := generate(1)
+		secondInputs, secondTargets := generate(1)
+		otherInputs, otherTargets := generate(2)
+
+		assert.Equal(t, firstInputs, secondInputs, name)
+		assert.Equal(t, firstTargets, secondTargets, name)
+		assert.NotEqual(t, []interface{}{firstInputs, firstTargets}, []interface{}{otherInputs, otherTargets}, "%s should depend on the seed", name)
+	}
+}
+
+func TestGenerateSpiral(t *testing.T) {
+	inputs, targets := generateSpiral(100, 3, 0, 1)
+
+	require.Len(t, inputs, 300)
+	requireClassCounts(t, targets, 3, 100)
+	assert.Equal(t, vector{0, 0}, inputs[0], "Every arm should start at the origin")
+	assert.InDelta(t, 1, math.Hypot(inputs[99][0], inputs[99][1]), 1e-12, "Every arm should end on the unit circle")
+}
+
+func TestGenerateBlobs(t *testing.T) {
+	inputs, targets := generateBlobs(50, 4, 3, 0.1, 1)
+
+	require.Len(t, inputs, 200)
+	requireClassCounts(t, targets, 4, 50)
+	for _, input := range inputs {
+		require.Len(t, input, 3)
+	}
+
+	assert.InDelta(t, inputs[0][0], inputs[49][0], 1, "Points of a class should be close to its center")
+}
+
+func TestGenerateMoonsAndCircles(t *testing.T) {
+	inputs, targets := generateMoons(30, 0, 1)
+	require.Len(t, inputs, 60)
+	requireClassCounts(t, targets, 2, 30)
+	for index, input := range inputs {
+		if targets[index] == 0 {
+			assert.InDelta(t, 1, math.Hypot(input[0], input[1]), 1e-12)
+		} else {
+			assert.InDelta(t, 1, math.Hypot(input[0]-1, input[1]-0.5), 1e-12)
+		}
+	}
+
+	inputs, targets = generateCircles(30, 0.3, 0, 1)
+	require.Len(t, inputs, 60)
+	requireClassCounts(t, targets, 2, 30)
+	for index, input := range inputs {
+		var radius float64 = 1
+		if targets[index] == 1 {
+			radius = 0.3
+		}
+
+		assert.InDelta(t, radius, math.Hypot(input[0], input[1]), 1e-12)
+	}
+}
+
+func TestGenerateSine(t *testing.T) {
+	inputs, targets := generateSine(101, 0, 1)
+
+	require.Len(t, inputs, 101)
+	require.Len(t, targets, 101)
+	assert.Equal(t, vector{0}, inputs[0])
+	assert.Equal(t, vector{1}, inputs[100])
+	assert.InDelta(t, 1, targets[25][0], 1e-12)
+	assert.InDelta(t, -1, targets[75][0], 1e-12)
+}
+
+func TestGeneratorPanics(t *testing.T) {
+	assert.Panics(t, func() { generateSpiral(0, 3, 0, 1) }, "Should panic without samples")
+	assert.Panics(t, func() { generateSpiral(10, 0, 0, 1) }, "Should panic without classes")
+	assert.Panics(t, func() { generateBlobs(10, 2, 0, 1, 1) }, "Should panic without features")
+	assert.Panics(t, func() { generateCircles(10, 1, 0, 1) }, "Should panic with factor 1")
+	assert.Panics(t, func() { generateSine(-1, 0, 1) }, "Should panic with negative samples")
+}
+
+func TestTrainOnBlobs(t *testing.T) {
+	inputs, targets := generateBlobs(30, 3, 2, 0.5, 3)
+	var net *network = newBatchingTestNetwork(1, 2, 8, 3)
+	var blobTrainer trainer = newTrainer(net, trainingConfig{epochs: 100, batchSize: 16, learningRateStart: 0.05, momentum: 0.9, shuffle: true, seed: 1})
+
+	require.NoError(t, blobTrainer.train(newFeatureScaler(scalerKindStandard).fitTransform(inputs), targets))
+	net.forward(newFeatureScaler(scalerKindStandard).fitTransform(inputs), targets)
+	assert.Greater(t, calculateAccuracy(net.lastOutput, targets), 0.95, "Well separated blobs should be learned")
+}
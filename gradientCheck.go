@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+const defaultGradientCheckEpsilon float64 = 1e-6
+
+type gradientCheckEntry struct {
+	name          string
+	analytic      float64
+	numerical     float64
+	relativeError float64
+}
+
+type gradientCheckReport struct {
+	entries []gradientCheckEntry
+}
+
+func newGradientCheckEntry(name string, analytic, numerical float64) gradientCheckEntry {
+	var scale float64 = math.Abs(analytic) + math.Abs(numerical)
+	var relativeError float64 = 0
+
+	if scale > 0 {
+		relativeError = math.Abs(analytic-numerical) / scale
+	}
+
+	return gradientCheckEntry{name: name, analytic: analytic, numerical: numerical, relativeError: relativeError}
+}
+
+func (r gradientCheckReport) maxRelativeError() float64 {
+	var maxError float64 = 0
+
+	for _, entry := range r.entries {
+		if entry.relativeError > maxError || math.IsNaN(entry.relativeError) {
+			maxError = entry.relativeError
+		}
+	}
+
+	return maxError
+}
+
+func (r gradientCheckReport) failures(tolerance float64) []gradientCheckEntry {
+	var failures []gradientCheckEntry
+
+	for _, entry := range r.entries {
+		if !(entry.relativeError <= tolerance) {
+			failures = append(failures, entry)
+		}
+	}
+
+	return failures
+}
+
+func (r gradientCheckReport) String() string {
+	var builder strings.Builder
+
+	for _, entry := range r.entries {
+		fmt.Fprintf(&builder, "%s: analytic %g numerical %g relative error %g\n", entry.name, entry.analytic, entry.numerical, entry.relativeError)
+	}
+
+	return builder.String()
+}
+
+func copyMatrix(m matrix) matrix {
+	var copied matrix = make(matrix, len(m))
+
+	for rowIndex, row := range m {
+		copied[rowIndex] = make(vector, len(row))
+		copy(copied[rowIndex], row)
+	}
+
+	return copied
+}
+
+// centralDifference estimates the derivative of objective with respect to *value, restoring *value afterwards.
+func centralDifference(value *float64, epsilon float64, objective func() float64) float64 {
+	var original float64 = *value
+
+	*value = original + epsilon
+	var plus float64 = objective()
+
+	*value = original - epsilon
+	var minus float64 = objective()
+
+	*value = original
+	return (plus - minus) / (2 * epsilon)
+}
+
+// checkParameterGradients compares the analytic gradients of t against the finite differences of objective.
+func checkParameterGradients(t trainable, analyticGradients vector, prefix string, epsilon float64, objective func() float64) []gradientCheckEntry {
+	var entries []gradientCheckEntry
+	var parameters vector = t.getParameters()
+
+	for parameterIndex := range parameters {
+		var numerical float64 = centralDifference(&parameters[parameterIndex], epsilon, func() float64 {
+			t.setParameters(parameters)
+			return objective()
+		})
+
+		var name string = prefix + t.describeParameter(parameterIndex)
+		entries = append(entries, newGradientCheckEntry(name, analyticGradients[parameterIndex], numerical))
+	}
+
+	t.setParameters(parameters)
+	return entries
+}
+
+func checkInputGradients(input matrix, analyticGradients matrix, epsilon float64, objective func(input matrix) float64) []gradientCheckEntry {
+	var entries []gradientCheckEntry
+	var perturbed matrix = copyMatrix(input)
+
+	for rowIndex, row := range perturbed {
+		for columnIndex := range row {
+			var numerical float64 = centralDifference(&row[columnIndex], epsilon, func() float64 {
+				return objective(perturbed)
+			})
+
+			var name string = fmt.Sprintf("input[%d][%d]", rowIndex, columnIndex)
+			entries = append(entries, newGradientCheckEntry(name, analyticGradients[rowIndex][columnIndex], numerical))
+		}
+	}
+
+	return entries
+}
+
+// checkComponentGradients checks the backward pass of c on input using the scalar objective sum(output * projection)
+// for a fixed random projection. Input derivatives are compared against the objective itself and parameter
+// gradients against the objective averaged over the batch, matching how layer computes them.
+func checkComponentGradients(c component, input matrix, epsilon float64) gradientCheckReport {
+	var projectionRandom *rand.Rand = rand.New(rand.NewSource(1))
+	var output matrix = c.forward(input)
+	var projection matrix = make(matrix, len(output))
+
+	for rowIndex, row := range output {
+		projection[rowIndex] = make(vector, len(row))
+		for columnIndex := range row {
+			projection[rowIndex][columnIndex] = projectionRandom.Float64()*2 - 1
+		}
+	}
+
+	var objective func(input matrix) float64 = func(input matrix) float64 {
+		var sum float64 = 0
+
+		for rowIndex, row := range c.forward(input) {
+			for columnIndex, value := range row {
+				sum += value * projection[rowIndex][columnIndex]
+			}
+		}
+
+		return sum
+	}
+
+	c.backward(projection)
+	var analyticInputGradients matrix = copyMatrix(c.getInputDerivatives())
+	var report gradientCheckReport
+
+	if t, ok := c.(trainable); ok {
+		var batchSize float64 = float64(len(input))
+		var analyticParameterGradients vector = t.getParameterGradients()
+
+		report.entries = append(report.entries, checkParameterGradients(t, analyticParameterGradients, "", epsilon, func() float64 {
+			return objective(input) / batchSize
+		})...)
+	}
+
+	report.entries = append(report.entries, checkInputGradients(input, analyticInputGradients, epsilon, objective)...)
+
+	c.forward(input)
+	c.backward(projection)
+	return report
+}
+
+// checkLossGradients checks the input derivatives of a loss against the sum of its per-sample losses.
+func checkLossGradients(l lossFunction, input matrix, targets []int, epsilon float64) gradientCheckReport {
+	l.forward(input, targets)
+	l.backward()
+
+	var analyticInputGradients matrix = copyMatrix(l.getInputDerivatives())
+	var report gradientCheckReport
+
+	report.entries = checkInputGradients(input, analyticInputGradients, epsilon, func(input matrix) float64 {
+		return vectorSum(l.forward(input, targets))
+	})
+
+	l.forward(input, targets)
+	l.backward()
+	return report
+}
+
+// checkNetworkGradients checks every parameter of every trainable component of n against the average loss,
+// and the input derivatives of the first component against the summed loss.
+func checkNetworkGradients(n *network, input matrix, targets []int, epsilon float64) gradientCheckReport {
+	n.forward(input, targets)
+	n.backward()
+
+	var report gradientCheckReport
+	var analyticInputGradients matrix = copyMatrix(n.getInputDerivatives())
+	var analyticParameterGradients []vector = make([]vector, len(n.components))
+
+	for index, c := range n.components {
+		if t, ok := c.(trainable); ok {
+			analyticParameterGradients[index] = t.getParameterGradients()
+		}
+	}
+
+	for index, c := range n.components {
+		if t, ok := c.(trainable); ok {
+			var prefix string = n.describeComponent(index) + " "
+
+			report.entries = append(report.entries, checkParameterGradients(t, analyticParameterGradients[index], prefix, epsilon, func() float64 {
+				return n.forward(input, targets)
+			})...)
+		}
+	}
+
+	var batchSize float64 = float64(len(input))
+	report.entries = append(report.entries, checkInputGradients(input, analyticInputGradients, epsilon, func(input matrix) float64 {
+		return n.forward(input, targets) * batchSize
+	})...)
+
+	n.forward(input, targets)
+	n.backward()
+	return report
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// doublingComponent multiplies its input by 2 but reports derivatives as if it multiplied by 3.
+type doublingComponent struct {
+	inputDerivatives matrix
+}
+
+func (d *doublingComponent) forward(input matrix) matrix {
+	return d.predict(input)
+}
+
+func (d doublingComponent) predict(input matrix) matrix {
+	var output matrix = copyMatrix(input)
+
+	for _, row := range output {
+		for index := range row {
+			row[index] *= 2
+		}
+	}
+
+	return output
+}
+
+func (d *doublingComponent) backward(forwardInputDerivatives matrix) {
+	d.inputDerivatives = copyMatrix(forwardInputDerivatives)
+
+	for _, row := range d.inputDerivatives {
+		for index := range row {
+			row[index] *= 3
+		}
+	}
+}
+
+func (d doublingComponent) getInputDerivatives() matrix {
+	return d.inputDerivatives
+}
+
+func TestGradientCheckDetectsWrongDerivatives(t *testing.T) {
+	var d doublingComponent
+	var report gradientCheckReport = checkComponentGradients(&d, matrix{{1, 2}, {3, 4}}, defaultGradientCheckEpsilon)
+
+	require.Len(t, report.failures(1e-6), 4, "Every input derivative should be reported as wrong")
+	require.InDelta(t, 0.2, report.maxRelativeError(), 1e-6, "Relative error of 3 against 2 should be 1/5")
+}
+
+func TestGradientCheckEntryRelativeError(t *testing.T) {
+	require.Equal(t, 0.0, newGradientCheckEntry("zero", 0, 0).relativeError, "Matching zero gradients should have no error")
+	require.Equal(t, 1.0, newGradientCheckEntry("sign", 1, -1).relativeError, "Opposite gradients should have error 1")
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// gradientClipping limits parameter gradients before the optimizer applies them. clipValue clips every gradient
+// value into [-clipValue, clipValue]. maxNorm then rescales the gradients of all trainables together so their
+// global L2 norm is at most maxNorm. A zero value disables either kind of clipping.
+type gradientClipping struct {
+	clipValue float64
+	maxNorm   float64
+}
+
+func newGradientClipping(clipValue, maxNorm float64) gradientClipping {
+	if clipValue < 0 {
+		panic(fmt.Sprintf("Can not clip gradients by negative value %f", clipValue))
+	}
+
+	if maxNorm < 0 {
+		panic(fmt.Sprintf("Can not clip gradients by negative norm %f", maxNorm))
+	}
+
+	return gradientClipping{clipValue: clipValue, maxNorm: maxNorm}
+}
+
+func globalGradientNorm(gradients []vector) float64 {
+	var squaredSum float64 = 0
+
+	for _, trainableGradients := range gradients {
+		for _, value := range trainableGradients {
+			squaredSum += value * value
+		}
+	}
+
+	return math.Sqrt(squaredSum)
+}
+
+// apply clips the gradients of trainables in place and returns their global L2 norm from before clipping.
+func (g gradientClipping) apply(trainables []trainable) float64 {
+	var gradients []vector = make([]vector, len(trainables))
+	for index, t := range trainables {
+		gradients[index] = t.getParameterGradients()
+	}
+
+	var norm float64 = globalGradientNorm(gradients)
+	if g.clipValue == 0 && g.maxNorm == 0 {
+		return norm
+	}
+
+	if g.clipValue != 0 {
+		for _, trainableGradients := range gradients {
+			for index, value := range trainableGradients {
+				trainableGradients[index] = clip(-g.clipValue, g.clipValue, value)
+			}
+		}
+	}
+
+	if g.maxNorm != 0 {
+		var clippedNorm float64 = globalGradientNorm(gradients)
+
+		if clippedNorm > g.maxNorm {
+			var scale float64 = g.maxNorm / clippedNorm
+
+			for _, trainableGradients := range gradients {
+				for index := range trainableGradients {
+					trainableGradients[index] *= scale
+				}
+			}
+		}
+	}
+
+	for index, t := range trainables {
+		t.setParameterGradients(gradients[index])
+	}
+
+	return norm
+}
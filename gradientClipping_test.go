@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func clippingTestTrainables() (*layer, *denseLayer) {
+	var l layer = newLayerExplicit(matrix{{0, 0}}, vector{0})
+	l.setParameterGradients(vector{3, -4, 0})
+
+	var d denseLayer = newDenseLayerExplicit(matrix{{0}, {0}}, vector{0, 0})
+	d.setParameterGradients(vector{0, 12, 0, 0})
+
+	return &l, &d
+}
+
+func TestGradientClippingDisabled(t *testing.T) {
+	l, d := clippingTestTrainables()
+
+	var norm float64 = newGradientClipping(0, 0).apply([]trainable{l, d})
+	assert.Equal(t, 13.0, norm, "Should report the global norm across all trainables")
+	assert.Equal(t, vector{3, -4, 0}, l.getParameterGradients(), "Should not change gradients when clipping is disabled")
+	assert.Equal(t, vector{0, 12, 0, 0}, d.getParameterGradients(), "Should not change gradients when clipping is disabled")
+}
+
+func TestGradientClippingByValue(t *testing.T) {
+	l, d := clippingTestTrainables()
+
+	var norm float64 = newGradientClipping(3.5, 0).apply([]trainable{l, d})
+	assert.Equal(t, 13.0, norm, "Should report the norm from before clipping")
+	assert.Equal(t, vector{3, -3.5, 0}, l.getParameterGradients())
+	assert.Equal(t, vector{0, 3.5, 0, 0}, d.getParameterGradients())
+}
+
+func TestGradientClippingByNorm(t *testing.T) {
+	l, d := clippingTestTrainables()
+
+	var norm float64 = newGradientClipping(0, 6.5).apply([]trainable{l, d})
+	assert.Equal(t, 13.0, norm, "Should report the norm from before clipping")
+	assert.Equal(t, vector{1.5, -2, 0}, l.getParameterGradients(), "Should scale every trainable by the same factor")
+	assert.Equal(t, vector{0, 6, 0, 0}, d.getParameterGradients(), "Should scale every trainable by the same factor")
+
+	l, d = clippingTestTrainables()
+	newGradientClipping(0, 20).apply([]trainable{l, d})
+	assert.Equal(t, vector{3, -4, 0}, l.getParameterGradients(), "Should not scale gradients whose norm is below the maximum")
+}
+
+func TestGradientClippingByValueThenNorm(t *testing.T) {
+	l, d := clippingTestTrainables()
+
+	newGradientClipping(4, 2.5).apply([]trainable{l, d})
+	var clipped float64 = globalGradientNorm([]vector{l.getParameterGradients(), d.getParameterGradients()})
+	assert.InDelta(t, 2.5, clipped, 1e-12, "Should rescale the value clipped gradients to the maximum norm")
+	assert.InDelta(t, 3*2.5/math.Sqrt(41), l.getParameterGradients()[0], 1e-12)
+}
+
+func TestGradientClippingPanics(t *testing.T) {
+	assert.Panics(t, func() { newGradientClipping(-1, 0) }, "Should panic with negative clip value")
+	assert.Panics(t, func() { newGradientClipping(0, -1) }, "Should panic with negative max norm")
+}
+
+func TestTrainerClipsGradients(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(6)
+	var recorder *metricsRecorder = &metricsRecorder{}
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 3, learningRateStart: 1, clipNorm: 1e-3}, recorder)
+
+	var before []vector = snapshotParameters(&net)
+	require.NoError(t, tr.train(inputs, targets))
+	require.Contains(t, recorder.last, "grad_norm", "Gradient norm should be reported")
+	assert.Greater(t, recorder.last["grad_norm"], 1e-3, "Should report the gradient norm from before clipping")
+
+	var change []vector = snapshotParameters(&net)
+	for index := range change {
+		for parameterIndex := range change[index] {
+			change[index][parameterIndex] -= before[index][parameterIndex]
+		}
+	}
+
+	assert.LessOrEqual(t, globalGradientNorm(change), 3*1e-3+1e-12, "Each step should move the parameters by at most the clipped norm")
+}
@@ -0,0 +1,77 @@
+package main
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative inference.proto
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// inferenceService implements the Inference gRPC service defined in inference.proto on top of a predictionServer,
+// so gRPC and HTTP validate and answer requests the same way.
+type inferenceService struct {
+	UnimplementedInferenceServer
+	model *predictionServer
+}
+
+func newGRPCServer(model *predictionServer, options ...grpc.ServerOption) *grpc.Server {
+	var server *grpc.Server = grpc.NewServer(options...)
+	RegisterInferenceServer(server, &inferenceService{model: model})
+	return server
+}
+
+func newPredictionReply(p prediction) *PredictionReply {
+	return &PredictionReply{Probabilities: p.Probabilities, Class: int32(p.Class), Label: p.Label}
+}
+
+func (s *inferenceService) Predict(ctx context.Context, request *PredictionRequest) (*PredictionReply, error) {
+	var inputs matrix = matrix{request.GetInput()}
+	if err := s.model.validateInputs(inputs); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	predictions, err := s.model.predictions(inputs)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return newPredictionReply(predictions[0]), nil
+}
+
+func (s *inferenceService) BatchPredict(ctx context.Context, request *BatchPredictionRequest) (*BatchPredictionReply, error) {
+	var inputs matrix = make(matrix, len(request.GetInputs()))
+	for index, row := range request.GetInputs() {
+		inputs[index] = row.GetValues()
+	}
+
+	if err := s.model.validateInputs(inputs); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	predictions, err := s.model.predictions(inputs)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var reply *BatchPredictionReply = &BatchPredictionReply{Predictions: make([]*PredictionReply, len(predictions))}
+	for index, p := range predictions {
+		reply.Predictions[index] = newPredictionReply(p)
+	}
+
+	return reply, nil
+}
+
+func (s *inferenceService) ModelInfo(ctx context.Context, request *ModelInfoRequest) (*ModelInfoReply, error) {
+	var info modelInfo = s.model.info()
+
+	return &ModelInfoReply{
+		InputCount: int32(info.InputCount),
+		ClassCount: int32(info.ClassCount),
+		Labels:     info.Labels,
+		Components: info.Components,
+		Loss:       info.Loss,
+	}, nil
+}
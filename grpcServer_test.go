@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newTestInferenceClient serves a small labelled network over an in-memory bufconn listener.
+func newTestInferenceClient(t *testing.T) (InferenceClient, *network) {
+	var l layer = newLayerExplicit(matrix{{1, 0}, {0, 1}, {-1, -1}}, vector{0, 0, 0})
+	var served network = newNetwork(&crossentropy{}, &l, &softmax{})
+	served.labels = []string{"first", "second", "neither"}
+
+	model, err := newPredictionServer(&served)
+	require.NoError(t, err)
+
+	var listener *bufconn.Listener = bufconn.Listen(1 << 20)
+	var server *grpc.Server = newGRPCServer(model)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	connection, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { connection.Close() })
+
+	return NewInferenceClient(connection), &served
+}
+
+func TestGRPCPredict(t *testing.T) {
+	client, served := newTestInferenceClient(t)
+
+	reply, err := client.Predict(context.Background(), &PredictionRequest{Input: []float64{0.2, 3}})
+	require.NoError(t, err)
+	assert.Equal(t, []float64(served.predict(matrix{{0.2, 3}})[0]), reply.GetProbabilities())
+	assert.Equal(t, int32(1), reply.GetClass())
+	assert.Equal(t, "second", reply.GetLabel())
+}
+
+func TestGRPCBatchPredict(t *testing.T) {
+	client, _ := newTestInferenceClient(t)
+
+	reply, err := client.BatchPredict(context.Background(), &BatchPredictionRequest{Inputs: []*InputRow{
+		{Values: []float64{3, 0}},
+		{Values: []float64{0, 3}},
+		{Values: []float64{-3, -3}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, reply.GetPredictions(), 3)
+
+	for index, label := range []string{"first", "second", "neither"} {
+		assert.Equal(t, int32(index), reply.GetPredictions()[index].GetClass())
+		assert.Equal(t, label, reply.GetPredictions()[index].GetLabel())
+	}
+}
+
+func TestGRPCModelInfo(t *testing.T) {
+	client, _ := newTestInferenceClient(t)
+
+	reply, err := client.ModelInfo(context.Background(), &ModelInfoRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), reply.GetInputCount())
+	assert.Equal(t, int32(3), reply.GetClassCount())
+	assert.Equal(t, []string{"first", "second", "neither"}, reply.GetLabels())
+	assert.Equal(t, []string{"*main.layer", "*main.softmax"}, reply.GetComponents())
+	assert.Equal(t, "*main.crossentropy", reply.GetLoss())
+}
+
+func TestGRPCRejectsInvalidInputs(t *testing.T) {
+	client, _ := newTestInferenceClient(t)
+
+	_, err := client.Predict(context.Background(), &PredictionRequest{Input: []float64{1}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "input 0 has 1 values but the model expects 2", status.Convert(err).Message())
+
+	_, err = client.BatchPredict(context.Background(), &BatchPredictionRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err), "An empty batch should be rejected")
+
+	_, err = client.BatchPredict(context.Background(), &BatchPredictionRequest{Inputs: []*InputRow{{Values: []float64{1, 2}}, {Values: []float64{1, 2, 3}}}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,527 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	spaceDiscrete   string = "discrete"
+	spaceUniform    string = "uniform"
+	spaceLogUniform string = "logUniform"
+)
+
+// parameterSpace is the set of values a hyperparameter is searched over: a list of discrete values, or a range
+// [low, high] sampled uniformly or log-uniformly. Log-uniform suits scale parameters like the learning rate, where
+// 0.001 to 0.01 matters as much as 0.1 to 1.
+type parameterSpace struct {
+	kind   string
+	values []float64
+	low    float64
+	high   float64
+}
+
+func discreteSpace(values ...float64) parameterSpace {
+	if len(values) == 0 {
+		panic("Can not create discrete space without values")
+	}
+
+	return parameterSpace{kind: spaceDiscrete, values: values}
+}
+
+func uniformSpace(low, high float64) parameterSpace {
+	if !(low < high) {
+		panic(fmt.Sprintf("Can not create uniform space with low %v not below high %v", low, high))
+	}
+
+	return parameterSpace{kind: spaceUniform, low: low, high: high}
+}
+
+func logUniformSpace(low, high float64) parameterSpace {
+	if !(low > 0 && low < high) {
+		panic(fmt.Sprintf("Can not create log-uniform space from %v to %v. Bounds must be positive and ordered", low, high))
+	}
+
+	return parameterSpace{kind: spaceLogUniform, low: low, high: high}
+}
+
+func (s parameterSpace) sample(random *rand.Rand) float64 {
+	switch s.kind {
+	case spaceDiscrete:
+		return s.values[random.Intn(len(s.values))]
+	case spaceUniform:
+		return s.low + random.Float64()*(s.high-s.low)
+	default:
+		return math.Exp(math.Log(s.low) + random.Float64()*(math.Log(s.high)-math.Log(s.low)))
+	}
+}
+
+// hyperparameters are the values of one trial by parameter name.
+type hyperparameters map[string]float64
+
+// int returns the value of name rounded to the nearest integer, for counts like epochs and layer widths.
+func (h hyperparameters) int(name string) int {
+	value, ok := h[name]
+	if !ok {
+		panic(fmt.Sprintf("Unknown hyperparameter %q", name))
+	}
+
+	return int(math.Round(value))
+}
+
+func (h hyperparameters) names() []string {
+	var names []string
+	for name := range h {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// trialBuilder builds the untrained network and the training config of a trial. Its layers are initialized from
+// random, which the search seeds with a seed derived from the trial seed; the seed of the returned config is
+// replaced by the trial seed itself.
+type trialBuilder func(parameters hyperparameters, random *rand.Rand) (network, trainingConfig)
+
+// searchData is what trials train on and are scored on.
+type searchData struct {
+	trainInputs       matrix
+	trainTargets      []int
+	validationInputs  matrix
+	validationTargets []int
+}
+
+// searchConfig configures a search. metric is loss, which is minimized, or accuracy, which is maximized, both
+// measured on the validation data. workers trials train at once.
+type searchConfig struct {
+	trials  int
+	workers int
+	seed    int64
+	metric  string
+}
+
+// trialResult is the outcome of a trial. metrics holds val_loss and val_accuracy on the validation data and loss
+// and accuracy on the training data. Trials whose training fails keep the error and rank last.
+type trialResult struct {
+	trial      int
+	seed       int64
+	parameters hyperparameters
+	epochs     int
+	metrics    map[string]float64
+	err        error
+}
+
+// searchResults holds the trials ranked from best to worst by the validation metric.
+type searchResults struct {
+	metric string
+	trials []trialResult
+}
+
+// searchTrial is a trial with the state it needs to train, so it can be resumed with a larger epoch budget.
+type searchTrial struct {
+	result  trialResult
+	net     network
+	trainer trainer
+}
+
+func checkSearch(data searchData, config searchConfig) {
+	if config.metric != "loss" && config.metric != "accuracy" {
+		panic(fmt.Sprintf("Can not rank trials by %q. Metric must be loss or accuracy", config.metric))
+	}
+
+	if config.workers <= 0 {
+		panic(fmt.Sprintf("Can not search with %d workers", config.workers))
+	}
+
+	if len(data.trainInputs) == 0 || len(data.validationInputs) == 0 {
+		panic("Can not search without training and validation data")
+	}
+}
+
+// gridSearchParameters returns every combination of the discrete spaces, varying the last name fastest.
+func gridSearchParameters(spaces map[string]parameterSpace) []hyperparameters {
+	var combinations []hyperparameters = []hyperparameters{{}}
+
+	for _, name := range sortedSpaceNames(spaces) {
+		var space parameterSpace = spaces[name]
+		if space.kind != spaceDiscrete {
+			panic(fmt.Sprintf("Can not grid search %s space of %q. Grid search needs discrete spaces", space.kind, name))
+		}
+
+		var extended []hyperparameters
+		for _, combination := range combinations {
+			for _, value := range space.values {
+				var next hyperparameters = hyperparameters{name: value}
+				for otherName, otherValue := range combination {
+					next[otherName] = otherValue
+				}
+				extended = append(extended, next)
+			}
+		}
+		combinations = extended
+	}
+
+	return combinations
+}
+
+// randomSearchParameters samples count combinations from spaces.
+func randomSearchParameters(spaces map[string]parameterSpace, count int, random *rand.Rand) []hyperparameters {
+	var names []string = sortedSpaceNames(spaces)
+	var combinations []hyperparameters = make([]hyperparameters, count)
+
+	for index := range combinations {
+		combinations[index] = hyperparameters{}
+		for _, name := range names {
+			combinations[index][name] = spaces[name].sample(random)
+		}
+	}
+
+	return combinations
+}
+
+func sortedSpaceNames(spaces map[string]parameterSpace) []string {
+	if len(spaces) == 0 {
+		panic("Can not search without parameter spaces")
+	}
+
+	var names []string
+	for name := range spaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// gridSearch trains a trial for every combination of the discrete spaces.
+func gridSearch(spaces map[string]parameterSpace, build trialBuilder, data searchData, config searchConfig) searchResults {
+	checkSearch(data, config)
+	return runSearch(gridSearchParameters(spaces), build, data, config)
+}
+
+// randomSearch trains config.trials trials with parameters sampled from spaces.
+func randomSearch(spaces map[string]parameterSpace, build trialBuilder, data searchData, config searchConfig) searchResults {
+	checkSearch(data, config)
+	if config.trials <= 0 {
+		panic(fmt.Sprintf("Can not run %d trials", config.trials))
+	}
+
+	return runSearch(randomSearchParameters(spaces, config.trials, rand.New(newSplitMix64Source(deriveSeed(config.seed, seedPurposeSampling)))), build, data, config)
+}
+
+func runSearch(combinations []hyperparameters, build trialBuilder, data searchData, config searchConfig) searchResults {
+	var trials []*searchTrial = newSearchTrials(combinations, build, config.seed)
+	trainTrials(trials, data, config.workers, 0)
+
+	var results []trialResult = make([]trialResult, len(trials))
+	for index, trial := range trials {
+		results[index] = trial.result
+	}
+
+	return newSearchResults(config.metric, results)
+}
+
+// newSearchTrials builds every trial from a random source seeded from the trial seed, so parallel training does not
+// change how they are initialized.
+func newSearchTrials(combinations []hyperparameters, build trialBuilder, seed int64) []*searchTrial {
+	var trials []*searchTrial = make([]*searchTrial, len(combinations))
+
+	for index, parameters := range combinations {
+		var trialSeed int64 = seed + int64(index)
+		net, training := build(parameters, rand.New(newSplitMix64Source(deriveSeed(trialSeed, seedPurposeInitialization))))
+		training.seed = trialSeed
+
+		trials[index] = &searchTrial{
+			result: trialResult{trial: index + 1, seed: trialSeed, parameters: parameters},
+			net:    net,
+		}
+		trials[index].trainer = newTrainer(&trials[index].net, training)
+	}
+
+	return trials
+}
+
+// trainTrials trains trials on workers goroutines and scores them on the validation data. A positive epochs
+// first sets every trial's epoch budget, so trials that were trained before continue where they stopped. Trials
+// that failed before are skipped.
+func trainTrials(trials []*searchTrial, data searchData, workers int, epochs int) {
+	var jobs chan *searchTrial = make(chan *searchTrial)
+	var waitGroup sync.WaitGroup
+
+	for worker := 0; worker < workers; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for trial := range jobs {
+				trial.train(data, epochs)
+			}
+		}()
+	}
+
+	for _, trial := range trials {
+		if trial.result.err == nil {
+			jobs <- trial
+		}
+	}
+	close(jobs)
+	waitGroup.Wait()
+}
+
+func (s *searchTrial) train(data searchData, epochs int) {
+	if epochs > 0 {
+		s.trainer.config.epochs = epochs
+	}
+
+	if err := s.trainer.train(data.trainInputs, data.trainTargets); err != nil {
+		s.result.err = err
+		s.result.metrics = nil
+		return
+	}
+
+	s.result.epochs = s.trainer.completedEpochs
+	s.result.metrics = map[string]float64{}
+	s.result.metrics["loss"], s.result.metrics["accuracy"] = evaluate(&s.net, data.trainInputs, data.trainTargets)
+	s.result.metrics[validationMetricPrefix+"loss"], s.result.metrics[validationMetricPrefix+"accuracy"] = evaluate(&s.net, data.validationInputs, data.validationTargets)
+}
+
+// better reports whether a ranks before b. Failed trials and NaN scores rank last, and ties keep trial order.
+func (r searchResults) better(a, b trialResult) bool {
+	var aScore, bScore float64 = r.score(a), r.score(b)
+	var aValid, bValid bool = !math.IsNaN(aScore), !math.IsNaN(bScore)
+
+	if aValid != bValid {
+		return aValid
+	}
+
+	if !aValid || aScore == bScore {
+		return a.trial < b.trial
+	}
+
+	if r.metric == "accuracy" {
+		return aScore > bScore
+	}
+
+	return aScore < bScore
+}
+
+// score is the validation metric of a trial, or NaN if it failed.
+func (r searchResults) score(result trialResult) float64 {
+	if result.err != nil {
+		return math.NaN()
+	}
+
+	return result.metrics[validationMetricPrefix+r.metric]
+}
+
+func newSearchResults(metric string, results []trialResult) searchResults {
+	var ranked searchResults = searchResults{metric: metric, trials: append([]trialResult{}, results...)}
+	sort.SliceStable(ranked.trials, func(i, j int) bool {
+		return ranked.better(ranked.trials[i], ranked.trials[j])
+	})
+
+	return ranked
+}
+
+// best returns the best trial.
+func (r searchResults) best() trialResult {
+	return r.trials[0]
+}
+
+func (r searchResults) parameterNames() []string {
+	if len(r.trials) == 0 {
+		return nil
+	}
+
+	return r.trials[0].parameters.names()
+}
+
+var searchMetricNames []string = []string{validationMetricPrefix + "loss", validationMetricPrefix + "accuracy", "loss", "accuracy"}
+
+// rows returns the header and a row per trial in rank order, with an error column for failed trials.
+func (r searchResults) rows() [][]string {
+	var header []string = append([]string{"rank", "trial", "seed", "epochs"}, r.parameterNames()...)
+	header = append(header, searchMetricNames...)
+	header = append(header, "error")
+
+	var rows [][]string = [][]string{header}
+	for rank, result := range r.trials {
+		var row []string = []string{strconv.Itoa(rank + 1), strconv.Itoa(result.trial), strconv.FormatInt(result.seed, 10), strconv.Itoa(result.epochs)}
+		for _, name := range r.parameterNames() {
+			row = append(row, strconv.FormatFloat(result.parameters[name], 'g', 6, 64))
+		}
+
+		for _, name := range searchMetricNames {
+			if result.err != nil {
+				row = append(row, "")
+				continue
+			}
+
+			row = append(row, strconv.FormatFloat(result.metrics[name], 'f', 4, 64))
+		}
+
+		if result.err != nil {
+			row = append(row, result.err.Error())
+		} else {
+			row = append(row, "")
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows
+}
+
+// writeTable writes the ranked results as an aligned text table.
+func (r searchResults) writeTable(destination io.Writer) error {
+	var rows [][]string = r.rows()
+	var widths []int = make([]int, len(rows[0]))
+	for _, row := range rows {
+		for column, cell := range row {
+			widths[column] = maxInt(widths[column], len([]rune(cell)))
+		}
+	}
+
+	for _, row := range rows {
+		var cells []string = make([]string, len(row))
+		for column, cell := range row {
+			cells[column] = fmt.Sprintf("%*s", widths[column], cell)
+		}
+
+		if _, err := fmt.Fprintln(destination, strings.TrimRight(strings.Join(cells, "  "), " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeCSV writes the ranked results as CSV.
+func (r searchResults) writeCSV(destination io.Writer) error {
+	var writer *csv.Writer = csv.NewWriter(destination)
+	if err := writer.WriteAll(r.rows()); err != nil {
+		return err
+	}
+
+	return writer.Error()
+}
+
+// newHoldoutSearchData holds out one of folds stratified folds of inputs and targets as validation data.
+func newHoldoutSearchData(inputs matrix, targets []int, folds int, seed int64) searchData {
+	if folds < 2 || len(inputs) < folds {
+		panic(fmt.Sprintf("Can not hold out one of %d folds of %d samples", folds, len(inputs)))
+	}
+
+	var split [][]int = stratifiedFolds(targets, folds, rand.New(newSplitMix64Source(deriveSeed(seed, seedPurposeSplit))))
+	var trainIndexes []int
+	for _, indexes := range split[:folds-1] {
+		trainIndexes = append(trainIndexes, indexes...)
+	}
+	sort.Ints(trainIndexes)
+
+	var data searchData
+	data.trainInputs, data.trainTargets = selectSamples(inputs, targets, trainIndexes)
+	data.validationInputs, data.validationTargets = selectSamples(inputs, targets, split[folds-1])
+	return data
+}
+
+// irisSearchSpaces are the hyperparameters `lnet search` tunes for the network of `lnet train`.
+var irisSearchSpaces map[string]parameterSpace = map[string]parameterSpace{
+	"learningRateStart": logUniformSpace(0.01, 1),
+	"learningRateDecay": logUniformSpace(1e-8, 1e-2),
+	"hidden":            discreteSpace(4, 8, 16, 32),
+	"epochs":            discreteSpace(500, 1000, 2000),
+}
+
+func buildIrisTrial(parameters hyperparameters, random *rand.Rand) (network, trainingConfig) {
+	var l1 layer = newLayerWithRandom(parameters.int("hidden"), 4, random)
+	var l2 layer = newLayerWithRandom(3, parameters.int("hidden"), random)
+	var net network = newNetwork(&crossentropy{}, &l1, &reluActivation{}, &l2, &reluActivation{}, &softmax{})
+	net.labels = irisSmallLabels
+
+	return net, trainingConfig{
+		epochs:             parameters.int("epochs"),
+		learningRateStart:  parameters["learningRateStart"],
+		learningRateDecay:  parameters["learningRateDecay"],
+		checkNumericHealth: true,
+	}
+}
+
+// runSearchCommand implements `lnet search [-scheduler random] [-trials 20] [-workers 4] [-seed 1] [-metric loss]
+// [-out results.csv]`, searching irisSearchSpaces validated on a stratified fifth of the small iris data. The
+// halving and hyperband schedulers replace the searched epochs with budgets from -min-epochs to -max-epochs.
+func runSearchCommand(args []string) error {
+	var flags *flag.FlagSet = flag.NewFlagSet("search", flag.ContinueOnError)
+	var config halvingConfig
+	var scheduler *string = flags.String("scheduler", "random", "random, halving or hyperband")
+	flags.IntVar(&config.trials, "trials", 20, "number of random or halving trials")
+	flags.IntVar(&config.workers, "workers", runtime.NumCPU(), "number of trials to train at once")
+	flags.Int64Var(&config.seed, "seed", 1, "seed for sampling, initialization and the validation split")
+	flags.StringVar(&config.metric, "metric", "loss", "validation metric to rank by, loss or accuracy")
+	flags.IntVar(&config.minEpochs, "min-epochs", 100, "first epoch budget of halving and hyperband")
+	flags.IntVar(&config.maxEpochs, "max-epochs", 2700, "last epoch budget of halving and hyperband")
+	flags.IntVar(&config.reduction, "reduction", 3, "halving and hyperband keep 1/reduction of the trials per rung")
+	var outPath *string = flags.String("out", "", "path to write the ranked results to as CSV")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if config.trials <= 0 || config.workers <= 0 || (config.metric != "loss" && config.metric != "accuracy") {
+		return fmt.Errorf("search needs positive -trials and -workers and -metric loss or accuracy")
+	}
+
+	if *scheduler != "random" && (config.minEpochs <= 0 || config.maxEpochs < config.minEpochs || config.reduction < 2) {
+		return fmt.Errorf("%s needs 0 < -min-epochs <= -max-epochs and -reduction of at least 2", *scheduler)
+	}
+
+	var search func(map[string]parameterSpace, trialBuilder, searchData) searchResults
+	switch *scheduler {
+	case "random":
+		search = func(spaces map[string]parameterSpace, build trialBuilder, data searchData) searchResults {
+			return randomSearch(spaces, build, data, config.searchConfig)
+		}
+	case "halving":
+		search = func(spaces map[string]parameterSpace, build trialBuilder, data searchData) searchResults {
+			return successiveHalving(spaces, build, data, config)
+		}
+	case "hyperband":
+		search = func(spaces map[string]parameterSpace, build trialBuilder, data searchData) searchResults {
+			return hyperband(spaces, build, data, config)
+		}
+	default:
+		return fmt.Errorf("unknown scheduler %q", *scheduler)
+	}
+
+	inputs, targets, err := extractIrisSmall()
+	if err != nil {
+		return err
+	}
+
+	var results searchResults = search(irisSearchSpaces, buildIrisTrial, newHoldoutSearchData(inputs, targets, 5, config.seed))
+	if err = results.writeTable(os.Stdout); err != nil {
+		return err
+	}
+
+	if *outPath == "" {
+		return nil
+	}
+
+	var buffer bytes.Buffer
+	if err = results.writeCSV(&buffer); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(*outPath, buffer.Bytes())
+}
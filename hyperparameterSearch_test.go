@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func searchTestData() searchData {
+	inputs, targets := generateBlobs(20, 3, 2, 0.8, 4)
+	return newHoldoutSearchData(newFeatureScaler(scalerKindStandard).fitTransform(inputs), targets, 4, 1)
+}
+
+func buildSearchTestTrial(parameters hyperparameters, random *rand.Rand) (network, trainingConfig) {
+	return newDenseClassifier(2, parameters.int("hidden"), 3, random), trainingConfig{epochs: parameters.int("epochs"), batchSize: 10, learningRateStart: parameters["learningRate"], momentum: 0.5, shuffle: true, checkNumericHealth: true}
+}
+
+func TestParameterSpaces(t *testing.T) {
+	var random *rand.Rand = rand.New(newSplitMix64Source(1))
+	var logSamples, uniformSamples vector
+
+	for index := 0; index < 2000; index++ {
+		logSamples = append(logSamples, logUniformSpace(0.001, 1).sample(random))
+		uniformSamples = append(uniformSamples, uniformSpace(-1, 1).sample(random))
+		assert.Contains(t, []float64{2, 3}, discreteSpace(2, 3).sample(random))
+	}
+
+	assert.GreaterOrEqual(t, vectorMin(logSamples), 0.001)
+	assert.LessOrEqual(t, vectorMax(logSamples), 1.0)
+	var belowHundredth int = 0
+	for _, value := range logSamples {
+		if value < 0.01 {
+			belowHundredth++
+		}
+	}
+	assert.InDelta(t, 2000/3, belowHundredth, 100, "A third of log-uniform samples from 0.001 to 1 should be below 0.01")
+
+	assert.GreaterOrEqual(t, vectorMin(uniformSamples), -1.0)
+	assert.Less(t, vectorMax(uniformSamples), 1.0)
+	assert.InDelta(t, 0, vectorSum(uniformSamples)/2000, 0.05)
+
+	assert.Panics(t, func() { discreteSpace() }, "Should panic without values")
+	assert.Panics(t, func() { uniformSpace(1, 1) }, "Should panic with empty range")
+	assert.Panics(t, func() { logUniformSpace(0, 1) }, "Should panic with zero bound")
+	assert.Panics(t, func() { hyperparameters{}.int("hidden") }, "Should panic with unknown name")
+}
+
+func TestGridSearchParameters(t *testing.T) {
+	var combinations []hyperparameters = gridSearchParameters(map[string]parameterSpace{
+		"b": discreteSpace(1, 2, 3),
+		"a": discreteSpace(10, 20),
+	})
+
+	assert.Equal(t, []hyperparameters{
+		{"a": 10, "b": 1}, {"a": 10, "b": 2}, {"a": 10, "b": 3},
+		{"a": 20, "b": 1}, {"a": 20, "b": 2}, {"a": 20, "b": 3},
+	}, combinations)
+
+	assert.Panics(t, func() { gridSearchParameters(map[string]parameterSpace{"a": uniformSpace(0, 1)}) }, "Should panic with continuous space")
+	assert.Panics(t, func() { gridSearchParameters(nil) }, "Should panic without spaces")
+}
+
+func TestGridSearch(t *testing.T) {
+	var results searchResults = gridSearch(map[string]parameterSpace{
+		"hidden":       discreteSpace(2, 8),
+		"epochs":       discreteSpace(1, 40),
+		"learningRate": discreteSpace(0.1),
+	}, buildSearchTestTrial, searchTestData(), searchConfig{workers: 3, seed: 1, metric: "loss"})
+
+	require.Len(t, results.trials, 4)
+	for index := 1; index < len(results.trials); index++ {
+		assert.LessOrEqual(t, results.trials[index-1].metrics["val_loss"], results.trials[index].metrics["val_loss"], "Trials should be ranked by validation loss")
+	}
+
+	assert.Equal(t, 40, results.best().epochs, "Training longer should win on separable blobs")
+	assert.Greater(t, results.best().metrics["val_accuracy"], 0.9)
+}
+
+func TestRandomSearchIsDeterministic(t *testing.T) {
+	var spaces map[string]parameterSpace = map[string]parameterSpace{
+		"hidden":       discreteSpace(4, 8),
+		"epochs":       discreteSpace(5, 10),
+		"learningRate": logUniformSpace(0.01, 0.5),
+	}
+
+	var sequential searchResults = randomSearch(spaces, buildSearchTestTrial, searchTestData(), searchConfig{trials: 6, workers: 1, seed: 3, metric: "accuracy"})
+	var parallel searchResults = randomSearch(spaces, buildSearchTestTrial, searchTestData(), searchConfig{trials: 6, workers: 4, seed: 3, metric: "accuracy"})
+
+	require.Len(t, sequential.trials, 6)
+	assert.Equal(t, sequential, parallel, "The number of workers should not change the results")
+	for index := 1; index < len(sequential.trials); index++ {
+		assert.GreaterOrEqual(t, sequential.trials[index-1].metrics["val_accuracy"], sequential.trials[index].metrics["val_accuracy"], "Trials should be ranked by validation accuracy")
+	}
+
+	var other searchResults = randomSearch(spaces, buildSearchTestTrial, searchTestData(), searchConfig{trials: 6, workers: 4, seed: 4, metric: "accuracy"})
+	assert.NotEqual(t, sequential.trials[0].parameters, other.trials[0].parameters)
+
+	rand.Seed(21)
+	var expected float64 = rand.Float64()
+	rand.Seed(21)
+	randomSearch(spaces, buildSearchTestTrial, searchTestData(), searchConfig{trials: 2, workers: 1, seed: 3, metric: "accuracy"})
+	assert.Equal(t, expected, rand.Float64(), "Searching should not seed or draw from the global random source")
+}
+
+func TestSearchRanksFailedTrialsLast(t *testing.T) {
+	var results searchResults = gridSearch(map[string]parameterSpace{
+		"hidden":       discreteSpace(4),
+		"epochs":       discreteSpace(5),
+		"learningRate": discreteSpace(math.Inf(1), 0.1),
+	}, buildSearchTestTrial, searchTestData(), searchConfig{workers: 2, seed: 1, metric: "loss"})
+
+	require.Len(t, results.trials, 2)
+	assert.NoError(t, results.trials[0].err)
+	assert.Error(t, results.trials[1].err, "An infinite learning rate should fail the numeric health check")
+	assert.Equal(t, 1, results.trials[1].trial)
+}
+
+func TestSearchResultsTables(t *testing.T) {
+	var results searchResults = newSearchResults("loss", []trialResult{
+		{trial: 1, seed: 7, epochs: 10, parameters: hyperparameters{"lr": 0.5}, metrics: map[string]float64{"val_loss": 0.9, "val_accuracy": 0.5, "loss": 0.8, "accuracy": 0.6}},
+		{trial: 2, seed: 8, parameters: hyperparameters{"lr": 10}, err: assert.AnError},
+		{trial: 3, seed: 9, epochs: 10, parameters: hyperparameters{"lr": 0.05}, metrics: map[string]float64{"val_loss": 0.3, "val_accuracy": 0.9, "loss": 0.2, "accuracy": 1}},
+	})
+
+	var table bytes.Buffer
+	require.NoError(t, results.writeTable(&table))
+	var lines []string = strings.Split(strings.TrimSpace(table.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"rank", "trial", "seed", "epochs", "lr", "val_loss", "val_accuracy", "loss", "accuracy", "error"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"1", "3", "9", "10", "0.05", "0.3000", "0.9000", "0.2000", "1.0000"}, strings.Fields(lines[1]))
+	assert.True(t, strings.HasPrefix(strings.TrimSpace(lines[3]), "3"), "Failed trials should rank last")
+	assert.Contains(t, lines[3], assert.AnError.Error())
+
+	var csvOutput bytes.Buffer
+	require.NoError(t, results.writeCSV(&csvOutput))
+	records, err := csv.NewReader(&csvOutput).ReadAll()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "1", "7", "10", "0.5", "0.9000", "0.5000", "0.8000", "0.6000", ""}, records[2])
+}
+
+func TestSearchPanics(t *testing.T) {
+	var spaces map[string]parameterSpace = map[string]parameterSpace{"hidden": discreteSpace(4), "epochs": discreteSpace(1), "learningRate": discreteSpace(0.1)}
+
+	assert.Panics(t, func() {
+		gridSearch(spaces, buildSearchTestTrial, searchTestData(), searchConfig{workers: 1, metric: "f1"})
+	}, "Should panic with unknown metric")
+	assert.Panics(t, func() { gridSearch(spaces, buildSearchTestTrial, searchTestData(), searchConfig{metric: "loss"}) }, "Should panic without workers")
+	assert.Panics(t, func() {
+		gridSearch(spaces, buildSearchTestTrial, searchData{}, searchConfig{workers: 1, metric: "loss"})
+	}, "Should panic without data")
+	assert.Panics(t, func() {
+		randomSearch(spaces, buildSearchTestTrial, searchTestData(), searchConfig{workers: 1, metric: "loss"})
+	}, "Should panic without trials")
+}
+
+func TestNewHoldoutSearchData(t *testing.T) {
+	var data searchData = searchTestData()
+
+	assert.Len(t, data.trainInputs, 45)
+	assert.Len(t, data.validationInputs, 15)
+	for class := 0; class < 3; class++ {
+		var count int = 0
+		for _, target := range data.validationTargets {
+			if target == class {
+				count++
+			}
+		}
+
+		assert.Equal(t, 5, count, "The validation data should hold a share of every class")
+	}
+
+	assert.Panics(t, func() { newHoldoutSearchData(matrix{{1}}, []int{0}, 2, 1) }, "Should panic with fewer samples than folds")
+	assert.Error(t, runSearchCommand([]string{"-trials", "0"}), "search needs trials")
+	assert.Error(t, runSearchCommand([]string{"-metric", "f1"}), "search needs a known metric")
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// IDX element types, the third byte of the magic number.
+const (
+	idxUnsignedByte byte = 0x08
+	idxSignedByte   byte = 0x09
+	idxShort        byte = 0x0b
+	idxInt          byte = 0x0c
+	idxFloat        byte = 0x0d
+	idxDouble       byte = 0x0e
+)
+
+var idxElementSizes map[byte]int = map[byte]int{
+	idxUnsignedByte: 1,
+	idxSignedByte:   1,
+	idxShort:        2,
+	idxInt:          4,
+	idxFloat:        4,
+	idxDouble:       8,
+}
+
+// maxIDXElements bounds the product of the dimensions so it can not overflow.
+const maxIDXElements int = 1 << 31
+
+// idxChunkElements is how many elements readIDX reads at a time. Memory grows with the elements actually read, so
+// a corrupt header claiming far more elements than the file holds fails on the truncated data instead of
+// allocating memory for all of them up front.
+const idxChunkElements int = 1 << 16
+
+// idxData is a decoded IDX file: its element type, its dimensions and its elements in row-major order.
+type idxData struct {
+	elementType byte
+	dimensions  []int
+	values      vector
+}
+
+// readIDX decodes the IDX format of the MNIST files: two zero bytes, the element type, the dimension count, a
+// big-endian uint32 size per dimension and then the big-endian elements. Gzip-compressed input is detected and
+// decompressed.
+func readIDX(source io.Reader) (idxData, error) {
+	var reader *bufio.Reader = bufio.NewReader(source)
+
+	magic, err := reader.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		decompressed, err := gzip.NewReader(reader)
+		if err != nil {
+			return idxData{}, err
+		}
+		defer decompressed.Close()
+
+		reader = bufio.NewReader(decompressed)
+	}
+
+	var header [4]byte
+	if _, err = io.ReadFull(reader, header[:]); err != nil {
+		return idxData{}, fmt.Errorf("can not read IDX header: %w", err)
+	}
+
+	if header[0] != 0 || header[1] != 0 {
+		return idxData{}, fmt.Errorf("invalid IDX magic number %x", header)
+	}
+
+	var elementType byte = header[2]
+	elementSize, ok := idxElementSizes[elementType]
+	if !ok {
+		return idxData{}, fmt.Errorf("unknown IDX element type 0x%02x", elementType)
+	}
+
+	if header[3] == 0 {
+		return idxData{}, errors.New("IDX data has no dimensions")
+	}
+
+	var dimensions []int = make([]int, header[3])
+	var elementCount int = 1
+	for index := range dimensions {
+		var size uint32
+		if err = binary.Read(reader, binary.BigEndian, &size); err != nil {
+			return idxData{}, fmt.Errorf("can not read IDX dimension %d: %w", index, err)
+		}
+
+		dimensions[index] = int(size)
+		elementCount *= int(size)
+		if elementCount > maxIDXElements {
+			return idxData{}, fmt.Errorf("IDX dimensions %v exceed %d elements", dimensions[:index+1], maxIDXElements)
+		}
+	}
+
+	var values vector = make(vector, 0, minInt(elementCount, idxChunkElements))
+	var raw []byte = make([]byte, minInt(elementCount, idxChunkElements)*elementSize)
+	for len(values) < elementCount {
+		var chunk []byte = raw[:minInt(elementCount-len(values), idxChunkElements)*elementSize]
+		if _, err = io.ReadFull(reader, chunk); err != nil {
+			return idxData{}, fmt.Errorf("IDX data is shorter than its dimensions %v: %w", dimensions, err)
+		}
+
+		for offset := 0; offset < len(chunk); offset += elementSize {
+			values = append(values, decodeIDXElement(elementType, chunk[offset:offset+elementSize]))
+		}
+	}
+
+	return idxData{elementType: elementType, dimensions: dimensions, values: values}, nil
+}
+
+// decodeIDXElement converts one big-endian element of elementType to a float64.
+func decodeIDXElement(elementType byte, element []byte) float64 {
+	switch elementType {
+	case idxSignedByte:
+		return float64(int8(element[0]))
+	case idxShort:
+		return float64(int16(binary.BigEndian.Uint16(element)))
+	case idxInt:
+		return float64(int32(binary.BigEndian.Uint32(element)))
+	case idxFloat:
+		return float64(math.Float32frombits(binary.BigEndian.Uint32(element)))
+	case idxDouble:
+		return math.Float64frombits(binary.BigEndian.Uint64(element))
+	}
+
+	return float64(element[0])
+}
+
+// readIDXImages reads an IDX file of unsigned byte images with one row per image, scaled from [0, 255] to [0, 1].
+// The rows share the decoded values instead of copying them.
+func readIDXImages(source io.Reader) (matrix, error) {
+	data, err := readIDX(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.elementType != idxUnsignedByte || len(data.dimensions) < 2 {
+		return nil, fmt.Errorf("IDX images must be unsigned bytes with at least 2 dimensions, got type 0x%02x with dimensions %v", data.elementType, data.dimensions)
+	}
+
+	var pixelCount int = 1
+	for _, size := range data.dimensions[1:] {
+		pixelCount *= size
+	}
+
+	for index := range data.values {
+		data.values[index] /= 255
+	}
+
+	var images matrix = make(matrix, data.dimensions[0])
+	for index := range images {
+		images[index] = data.values[index*pixelCount : (index+1)*pixelCount : (index+1)*pixelCount]
+	}
+
+	return images, nil
+}
+
+// readIDXLabels reads a one-dimensional IDX file of unsigned byte class labels.
+func readIDXLabels(source io.Reader) ([]int, error) {
+	data, err := readIDX(source)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.elementType != idxUnsignedByte || len(data.dimensions) != 1 {
+		return nil, fmt.Errorf("IDX labels must be unsigned bytes with 1 dimension, got type 0x%02x with dimensions %v", data.elementType, data.dimensions)
+	}
+
+	var labels []int = make([]int, len(data.values))
+	for index, value := range data.values {
+		labels[index] = int(value)
+	}
+
+	return labels, nil
+}
+
+// loadIDXDataset reads an images and a labels IDX file, each plain or gzip-compressed, into normalized inputs and
+// targets.
+func loadIDXDataset(imagesPath, labelsPath string) (matrix, []int, error) {
+	imagesFile, err := os.Open(imagesPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer imagesFile.Close()
+
+	inputs, err := readIDXImages(imagesFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", imagesPath, err)
+	}
+
+	labelsFile, err := os.Open(labelsPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer labelsFile.Close()
+
+	targets, err := readIDXLabels(labelsFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", labelsPath, err)
+	}
+
+	if len(inputs) != len(targets) {
+		return nil, nil, newShapeMismatchError(len(inputs), len(targets), "%s has %d labels but %s has %d images", labelsPath, len(targets), imagesPath, len(inputs))
+	}
+
+	return inputs, targets, nil
+}
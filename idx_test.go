@@ -0,0 +1,169 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// encodeIDX builds an IDX file from its element type, dimensions and raw big-endian elements.
+func encodeIDX(elementType byte, dimensions []int, elements []byte, compress bool) []byte {
+	var buffer bytes.Buffer
+	buffer.Write([]byte{0, 0, elementType, byte(len(dimensions))})
+	for _, size := range dimensions {
+		binary.Write(&buffer, binary.BigEndian, uint32(size))
+	}
+	buffer.Write(elements)
+
+	if !compress {
+		return buffer.Bytes()
+	}
+
+	var compressed bytes.Buffer
+	var writer *gzip.Writer = gzip.NewWriter(&compressed)
+	writer.Write(buffer.Bytes())
+	writer.Close()
+	return compressed.Bytes()
+}
+
+func TestReadIDXImages(t *testing.T) {
+	var pixels []byte = []byte{0, 255, 51, 102, 0, 0, 255, 255}
+
+	for _, compress := range []bool{false, true} {
+		images, err := readIDXImages(bytes.NewReader(encodeIDX(idxUnsignedByte, []int{2, 2, 2}, pixels, compress)))
+		require.NoError(t, err)
+		assert.Equal(t, matrix{{0, 1, 0.2, 0.4}, {0, 0, 1, 1}}, images, "Images should be flattened and scaled to [0, 1]")
+	}
+
+	images, err := readIDXImages(bytes.NewReader(encodeIDX(idxUnsignedByte, []int{0, 28, 28}, nil, false)))
+	require.NoError(t, err)
+	assert.Empty(t, images)
+}
+
+func TestReadIDXLabels(t *testing.T) {
+	for _, compress := range []bool{false, true} {
+		labels, err := readIDXLabels(bytes.NewReader(encodeIDX(idxUnsignedByte, []int{3}, []byte{7, 0, 9}, compress)))
+		require.NoError(t, err)
+		assert.Equal(t, []int{7, 0, 9}, labels)
+	}
+}
+
+func TestReadIDXElementTypes(t *testing.T) {
+	var cases map[byte][]byte = map[byte][]byte{
+		idxSignedByte: {0xfe},
+		idxShort:      {0xff, 0xfe},
+		idxInt:        {0xff, 0xff, 0xff, 0xfe},
+		idxFloat:      {0xc0, 0x00, 0x00, 0x00},
+		idxDouble:     {0xc0, 0x00, 0, 0, 0, 0, 0, 0},
+	}
+
+	for elementType, element := range cases {
+		data, err := readIDX(bytes.NewReader(encodeIDX(elementType, []int{1}, element, false)))
+		require.NoError(t, err)
+		assert.Equal(t, vector{-2}, data.values, "element type 0x%02x", elementType)
+	}
+}
+
+func TestReadIDXErrors(t *testing.T) {
+	var files map[string][]byte = map[string][]byte{
+		"empty":           {},
+		"bad magic":       {1, 0, idxUnsignedByte, 1, 0, 0, 0, 0},
+		"unknown type":    {0, 0, 0x42, 1, 0, 0, 0, 0},
+		"no dimensions":   {0, 0, idxUnsignedByte, 0},
+		"short dimension": {0, 0, idxUnsignedByte, 1, 0, 0},
+		"truncated":       encodeIDX(idxUnsignedByte, []int{2, 2}, []byte{1, 2, 3}, false),
+		"too large":       encodeIDX(idxUnsignedByte, []int{1 << 20, 1 << 20}, nil, false),
+		"huge but short":  encodeIDX(idxDouble, []int{1 << 30}, make([]byte, 16), false),
+		"corrupt gzip":    {0x1f, 0x8b, 0, 0},
+	}
+
+	for name, contents := range files {
+		_, err := readIDX(bytes.NewReader(contents))
+		assert.Error(t, err, name)
+	}
+
+	_, err := readIDXImages(bytes.NewReader(encodeIDX(idxUnsignedByte, []int{2}, []byte{1, 2}, false)))
+	assert.Error(t, err, "Images need at least 2 dimensions")
+	_, err = readIDXImages(bytes.NewReader(encodeIDX(idxFloat, []int{1, 1}, make([]byte, 4), false)))
+	assert.Error(t, err, "Images have to be unsigned bytes")
+	_, err = readIDXLabels(bytes.NewReader(encodeIDX(idxUnsignedByte, []int{1, 1}, []byte{1}, false)))
+	assert.Error(t, err, "Labels have to be one-dimensional")
+}
+
+// writeSyntheticMNIST writes a train and a t10k split of 4x4 images to dir. Class 0 images are bright on the left
+// half, class 1 images on the right half. The train split is gzip-compressed.
+func writeSyntheticMNIST(t *testing.T, dir string, trainCount, testCount int) {
+	for _, split := range []struct {
+		prefix   string
+		count    int
+		compress bool
+	}{{"train", trainCount, true}, {"t10k", testCount, false}} {
+		var pixels []byte
+		var labels []byte
+		for index := 0; index < split.count; index++ {
+			var class byte = byte(index % 2)
+			for pixel := 0; pixel < 16; pixel++ {
+				var bright bool = (pixel%4 < 2) == (class == 0)
+				var value byte = byte((index * 7 * (pixel + 1)) % 40)
+				if bright {
+					value += 200
+				}
+				pixels = append(pixels, value)
+			}
+			labels = append(labels, class)
+		}
+
+		var suffix string = ""
+		if split.compress {
+			suffix = ".gz"
+		}
+
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, split.prefix+"-images-idx3-ubyte"+suffix), encodeIDX(idxUnsignedByte, []int{split.count, 4, 4}, pixels, split.compress), 0644))
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, split.prefix+"-labels-idx1-ubyte"+suffix), encodeIDX(idxUnsignedByte, []int{split.count}, labels, split.compress), 0644))
+	}
+}
+
+func TestLoadIDXDataset(t *testing.T) {
+	var dir string = t.TempDir()
+	writeSyntheticMNIST(t, dir, 6, 4)
+
+	inputs, targets, err := loadMNISTSplit(dir, "train")
+	require.NoError(t, err)
+	assert.Len(t, inputs, 6)
+	assert.Len(t, inputs[0], 16)
+	assert.Equal(t, []int{0, 1, 0, 1, 0, 1}, targets)
+
+	_, _, err = loadIDXDataset(filepath.Join(dir, "t10k-images-idx3-ubyte"), filepath.Join(dir, "train-labels-idx1-ubyte.gz"))
+	var shapeErr *shapeMismatchError
+	assert.True(t, errors.As(err, &shapeErr), "Image and label counts have to match")
+
+	_, _, err = loadMNISTSplit(t.TempDir(), "train")
+	assert.Error(t, err, "Missing files should be an error")
+}
+
+func TestTrainMNIST(t *testing.T) {
+	var dir string = t.TempDir()
+	writeSyntheticMNIST(t, dir, 40, 10)
+	var savePath string = filepath.Join(t.TempDir(), "mnist.json")
+	var output bytes.Buffer
+
+	accuracy, err := trainMNIST(mnistOptions{dir: dir, epochs: 20, batchSize: 8, hiddenSize: 8, learningRate: 0.1, momentum: 0.9, seed: 1, savePath: savePath}, &output)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, accuracy, "The synthetic classes should be separable")
+	assert.True(t, strings.Contains(output.String(), "test accuracy: 1.0000"), output.String())
+
+	loaded, err := loadNetwork(savePath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "1", "2", "3", "4", "5", "6", "7", "8", "9"}, loaded.labels)
+
+	assert.Error(t, runMNIST([]string{}), "mnist requires -dir")
+	assert.Error(t, runMNIST([]string{"-dir", t.TempDir()}), "Missing files should be an error")
+}
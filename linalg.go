@@ -0,0 +1,156 @@
+package main
+
+import "fmt"
+
+// gemmBlockSize is the edge length of the square tiles used by gemm to keep its working set in cache.
+const gemmBlockSize int = 64
+
+// denseMatrix stores a matrix as a single row-major slice instead of a slice of rows.
+type denseMatrix struct {
+	rows int
+	cols int
+	data []float64
+}
+
+func newDenseMatrix(rows, cols int) denseMatrix {
+	if rows < 0 || cols < 0 {
+		panic(fmt.Sprintf("Can not create dense matrix with shape %dx%d", rows, cols))
+	}
+
+	return denseMatrix{rows: rows, cols: cols, data: make([]float64, rows*cols)}
+}
+
+func denseMatrixFromMatrix(m matrix) denseMatrix {
+	var rows int = len(m)
+	if rows == 0 {
+		return denseMatrix{}
+	}
+
+	var cols int = len(m[0])
+	var d denseMatrix = newDenseMatrix(rows, cols)
+
+	for rowIndex, row := range m {
+		if len(row) != cols {
+			panic(fmt.Sprintf("Can not create dense matrix from rows of differing lengths %d and %d", cols, len(row)))
+		}
+
+		copy(d.data[rowIndex*cols:(rowIndex+1)*cols], row)
+	}
+
+	return d
+}
+
+func (d denseMatrix) row(index int) vector {
+	return d.data[index*d.cols : (index+1)*d.cols]
+}
+
+func (d denseMatrix) at(rowIndex, colIndex int) float64 {
+	return d.data[rowIndex*d.cols+colIndex]
+}
+
+func (d denseMatrix) toMatrix() matrix {
+	var m matrix = make(matrix, d.rows)
+
+	for rowIndex := range m {
+		var row vector = make(vector, d.cols)
+		copy(row, d.row(rowIndex))
+		m[rowIndex] = row
+	}
+
+	return m
+}
+
+func (d denseMatrix) transpose() denseMatrix {
+	var t denseMatrix = newDenseMatrix(d.cols, d.rows)
+
+	for rowIndex := 0; rowIndex < d.rows; rowIndex++ {
+		for colIndex := 0; colIndex < d.cols; colIndex++ {
+			t.data[colIndex*t.cols+rowIndex] = d.data[rowIndex*d.cols+colIndex]
+		}
+	}
+
+	return t
+}
+
+// gemm computes c = op(a) * op(b), where op transposes its operand when the matching flag is set.
+// For every element of c the products are accumulated in ascending order of the shared dimension,
+// so the result is bit-for-bit identical to a naive triple loop.
+func gemm(transA, transB bool, a, b denseMatrix, c *denseMatrix) {
+	if transA {
+		a = a.transpose()
+	}
+	if transB {
+		b = b.transpose()
+	}
+
+	if a.cols != b.rows {
+		panic(fmt.Sprintf("Can not multiply matrices with shapes %dx%d and %dx%d", a.rows, a.cols, b.rows, b.cols))
+	}
+
+	if c.rows != a.rows || c.cols != b.cols {
+		panic(fmt.Sprintf("Output matrix shape %dx%d does not match product shape %dx%d", c.rows, c.cols, a.rows, b.cols))
+	}
+
+	for index := range c.data {
+		c.data[index] = 0
+	}
+
+	computePool().run(a.rows, func(start, end int) {
+		gemmRows(a, b, c, start, end)
+	})
+}
+
+// gemmRows accumulates rows [rowStart, rowEnd) of a * b into c one cache tile at a time.
+func gemmRows(a, b denseMatrix, c *denseMatrix, rowStart, rowEnd int) {
+	var shared int = a.cols
+	var cols int = b.cols
+
+	for iBlock := rowStart; iBlock < rowEnd; iBlock += gemmBlockSize {
+		var iEnd int = minInt(iBlock+gemmBlockSize, rowEnd)
+
+		for kBlock := 0; kBlock < shared; kBlock += gemmBlockSize {
+			var kEnd int = minInt(kBlock+gemmBlockSize, shared)
+
+			for jBlock := 0; jBlock < cols; jBlock += gemmBlockSize {
+				var jEnd int = minInt(jBlock+gemmBlockSize, cols)
+				var width int = jEnd - jBlock
+
+				for i := iBlock; i < iEnd; i++ {
+					var cRow []float64 = c.data[i*cols+jBlock : i*cols+jEnd]
+					var k int = kBlock
+
+					// Four rows of b are folded in per pass over cRow. Each element still receives its
+					// products one at a time in ascending k order, keeping the summation order intact.
+					for ; k+3 < kEnd; k += 4 {
+						var a0 float64 = a.data[i*shared+k]
+						var a1 float64 = a.data[i*shared+k+1]
+						var a2 float64 = a.data[i*shared+k+2]
+						var a3 float64 = a.data[i*shared+k+3]
+						var b0 []float64 = b.data[k*cols+jBlock:][:width]
+						var b1 []float64 = b.data[(k+1)*cols+jBlock:][:width]
+						var b2 []float64 = b.data[(k+2)*cols+jBlock:][:width]
+						var b3 []float64 = b.data[(k+3)*cols+jBlock:][:width]
+
+						for j := range cRow {
+							var sum float64 = cRow[j]
+							sum += a0 * b0[j]
+							sum += a1 * b1[j]
+							sum += a2 * b2[j]
+							sum += a3 * b3[j]
+							cRow[j] = sum
+						}
+					}
+
+					for ; k < kEnd; k++ {
+						var aValue float64 = a.data[i*shared+k]
+						var bRow []float64 = b.data[k*cols+jBlock:][:width]
+
+						for j := range cRow {
+							cRow[j] += aValue * bRow[j]
+						}
+					}
+				}
+			}
+		}
+	}
+}
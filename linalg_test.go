@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func naiveMatMul(a, b matrix) matrix {
+	var output matrix = make(matrix, len(a))
+
+	for rowIndex := range output {
+		output[rowIndex] = make(vector, len(b[0]))
+
+		for colIndex := range output[rowIndex] {
+			var sum float64
+			for sharedIndex := range b {
+				sum += a[rowIndex][sharedIndex] * b[sharedIndex][colIndex]
+			}
+
+			output[rowIndex][colIndex] = sum
+		}
+	}
+
+	return output
+}
+
+func TestGemm(t *testing.T) {
+	rand.Seed(1)
+	var a matrix = randomMatrix(90, 150)
+	var b matrix = randomMatrix(150, 70)
+	var expected matrix = naiveMatMul(a, b)
+
+	var denseA denseMatrix = denseMatrixFromMatrix(a)
+	var denseB denseMatrix = denseMatrixFromMatrix(b)
+
+	var c denseMatrix = newDenseMatrix(90, 70)
+	gemm(false, false, denseA, denseB, &c)
+	require.Equal(t, expected, c.toMatrix(), "gemm should match a naive matrix multiply")
+
+	gemm(true, true, denseA.transpose(), denseB.transpose(), &c)
+	require.Equal(t, expected, c.toMatrix(), "gemm with transposed operands should match a naive matrix multiply")
+}
+
+func TestGemmPanics(t *testing.T) {
+	var a denseMatrix = newDenseMatrix(2, 3)
+	var c denseMatrix = newDenseMatrix(2, 2)
+
+	assert.Panics(t, func() { gemm(false, false, a, a, &c) }, "Should panic with mismatched inner dimensions")
+	assert.Panics(t, func() { gemm(false, true, a, newDenseMatrix(3, 3), &c) }, "Should panic with output shape not matching product shape")
+}
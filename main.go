@@ -1,15 +1,55 @@
 package main
 
 import (
-	"fmt"
+	"flag"
+	"log"
 	"math/rand"
+	"os"
 	"time"
 )
 
+// main runs `lnet serve ...`, `lnet mnist ...`, `lnet search ...` or `lnet [train] ...`, training on the small
+// iris data when no command is given.
 func main() {
+	var args []string = os.Args[1:]
+	var command string = "train"
+	if len(args) > 0 && (args[0] == "train" || args[0] == "serve" || args[0] == "mnist" || args[0] == "search") {
+		command = args[0]
+		args = args[1:]
+	}
+
+	var err error
+	switch command {
+	case "serve":
+		err = runServe(args)
+	case "mnist":
+		err = runMNIST(args)
+	case "search":
+		err = runSearchCommand(args)
+	default:
+		err = runTrain(args)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// runTrain implements `lnet train [-save model.json]`.
+func runTrain(args []string) error {
+	var flags *flag.FlagSet = flag.NewFlagSet("train", flag.ContinueOnError)
+	var savePath *string = flags.String("save", "", "path to save the trained network to")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
 	rand.Seed(time.Now().UTC().UnixNano())
 
-	var inputs, targets = extractIrisSmall()
+	inputs, targets, err := extractIrisSmall()
+	if err != nil {
+		return err
+	}
 
 	var l1 layer = newLayer(10, 4)
 	var relu1 reluActivation = reluActivation{}
@@ -20,76 +60,38 @@ func main() {
 	var softmaxActivation softmax = softmax{}
 	var crossentropyLoss crossentropy = crossentropy{}
 
+	var net network = newNetwork(&crossentropyLoss, &l1, &relu1, &l2, &relu2, &softmaxActivation)
+	net.labels = irisSmallLabels
+
 	const epochs int = 10000
 	const learningRateStart float64 = 1
 	const learningRateDecay float64 = 0.00000001
 	const logRate int = 100
+	const earlyStoppingPatience int = 500
+	const earlyStoppingMinDelta float64 = 0.000001
 
-	var learningRate float64 = learningRateStart
-	var currentEpoch int = 0
-
-	var forward func() = func() {
-		var l1Output matrix = l1.forward(inputs)
-		var relu1Output matrix = relu1.forward(l1Output)
-
-		var l2Output matrix = l2.forward(relu1Output)
-		var relu2Output matrix = relu2.forward(l2Output)
-
-		var softmaxOutput matrix = softmaxActivation.forward(relu2Output)
-		crossentropyLoss.forward(softmaxOutput, targets)
+	var config trainingConfig = trainingConfig{
+		epochs:            epochs,
+		learningRateStart: learningRateStart,
+		learningRateDecay: learningRateDecay,
 	}
 
-	var backward func() = func() {
-		crossentropyLoss.backward()
-		var crossentropyInputDerivatives matrix = crossentropyLoss.getInputDerivatives()
-
-		softmaxActivation.backward(crossentropyInputDerivatives)
-		var softmaxInputDerivatives matrix = softmaxActivation.getInputDerivatives()
-
-		relu2.backward(softmaxInputDerivatives)
-		var relu2InputDerivatives matrix = relu2.getInputDerivatives()
-
-		l2.backward(relu2InputDerivatives)
-		var l2InputDerivatives matrix = l2.getInputDerivatives()
+	var earlyStopping *earlyStoppingCallback = newEarlyStoppingCallback(earlyStoppingConfig{
+		monitor:            "loss",
+		patience:           earlyStoppingPatience,
+		minDelta:           earlyStoppingMinDelta,
+		restoreBestWeights: true,
+	})
 
-		relu1.backward(l2InputDerivatives)
-		var relu1InputDerivatives matrix = relu1.getInputDerivatives()
+	var t trainer = newTrainer(&net, config, newLoggingCallback(os.Stdout, logRate), earlyStopping)
 
-		l1.backward(relu1InputDerivatives)
+	if err = t.train(inputs, targets); err != nil {
+		return err
 	}
 
-	var optimizeLayer func(*layer) = func(l *layer) {
-		for index := range l.neurons {
-			var n *neuron = &l.neurons[index]
-
-			for weightIndex, derivativeValue := range n.derivativeWeights {
-				n.weights[weightIndex] = n.weights[weightIndex] + (-1 * derivativeValue * learningRate)
-			}
-
-			n.bias = n.bias + (-1 * n.derivativeBias * learningRate)
-		}
+	if *savePath != "" {
+		return saveNetwork(&net, *savePath)
 	}
 
-	var optimize func() = func() {
-		optimizeLayer(&l1)
-		optimizeLayer(&l2)
-	}
-
-	var updateLearningRate func() = func() {
-		learningRate = learningRateStart * (1 / (1 + learningRateDecay*float64(currentEpoch)))
-	}
-
-	for i := 0; i < epochs; i++ {
-		currentEpoch = i + 1
-		updateLearningRate()
-
-		forward()
-		backward()
-		optimize()
-
-		if i%logRate == 0 {
-			var averageLoss float64 = crossentropyLoss.calculateAverageLoss()
-			fmt.Printf("Learning Rate: %f\nEpoch %d Average Loss: %f\n\n", learningRate, currentEpoch, averageLoss)
-		}
-	}
+	return nil
 }
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	missingFail      string = "error"
+	missingDropRow   string = "dropRow"
+	missingMean      string = "mean"
+	missingMedian    string = "median"
+	missingMode      string = "mode"
+	missingConstant  string = "constant"
+	missingIndicator string = "indicator"
+)
+
+// defaultMissingTokens are recognized as missing in every column, compared case-insensitively after trimming
+// spaces.
+var defaultMissingTokens []string = []string{"", "na", "nan", "n/a", "null"}
+
+// missingValuePolicy says what to do with a missing cell of a column:
+//   - error: fail the transformation
+//   - dropRow: drop the whole row
+//   - mean, median: replace the cell with the fitted mean or median of the column, which has to be numeric
+//   - mode: replace the cell with the fitted most frequent value of the column
+//   - constant: replace the cell with value
+//   - indicator: replace the cell with value, or 0 if it is empty, and append a column that is 1 for missing and
+//     0 for present cells
+type missingValuePolicy struct {
+	strategy string
+	value    string
+}
+
+// missingValueHandler recognizes missing cells in string records and applies a policy per column. Statistics for
+// mean, median and mode imputation are fitted on training records and reused for every later transformation.
+type missingValueHandler struct {
+	tokens        map[string]bool
+	defaultPolicy missingValuePolicy
+	policies      map[int]missingValuePolicy
+	fills         map[int]string
+	fitted        bool
+}
+
+// missingValueReport counts the cells a transformation affected.
+type missingValueReport struct {
+	rows            int
+	rowsWithMissing int
+	missingCells    int
+	droppedRows     int
+	imputedCells    int
+	missingByColumn map[int]int
+}
+
+func (r missingValueReport) String() string {
+	var columns []int
+	for columnIndex := range r.missingByColumn {
+		columns = append(columns, columnIndex)
+	}
+	sort.Ints(columns)
+
+	var perColumn []string
+	for _, columnIndex := range columns {
+		perColumn = append(perColumn, fmt.Sprintf("column %d: %d", columnIndex, r.missingByColumn[columnIndex]))
+	}
+
+	return fmt.Sprintf("%d of %d rows had missing values, %d missing cells, %d imputed cells, %d dropped rows (%s)", r.rowsWithMissing, r.rows, r.missingCells, r.imputedCells, r.droppedRows, strings.Join(perColumn, ", "))
+}
+
+func checkMissingValuePolicy(policy missingValuePolicy) {
+	switch policy.strategy {
+	case missingFail, missingDropRow, missingMean, missingMedian, missingMode, missingConstant, missingIndicator:
+		return
+	}
+
+	panic(fmt.Sprintf("Unknown missing value strategy %q", policy.strategy))
+}
+
+// newMissingValueHandler creates a handler that applies defaultPolicy to every column without its own policy and
+// recognizes extraTokens as missing in addition to defaultMissingTokens.
+func newMissingValueHandler(defaultPolicy missingValuePolicy, extraTokens ...string) *missingValueHandler {
+	checkMissingValuePolicy(defaultPolicy)
+
+	var tokens map[string]bool = map[string]bool{}
+	for _, token := range append(append([]string{}, defaultMissingTokens...), extraTokens...) {
+		tokens[strings.ToLower(strings.TrimSpace(token))] = true
+	}
+
+	return &missingValueHandler{tokens: tokens, defaultPolicy: defaultPolicy, policies: map[int]missingValuePolicy{}, fills: map[int]string{}}
+}
+
+func (h *missingValueHandler) setPolicy(columnIndex int, policy missingValuePolicy) {
+	checkMissingValuePolicy(policy)
+	h.policies[columnIndex] = policy
+}
+
+func (h missingValueHandler) policy(columnIndex int) missingValuePolicy {
+	if policy, ok := h.policies[columnIndex]; ok {
+		return policy
+	}
+
+	return h.defaultPolicy
+}
+
+func (h missingValueHandler) isMissing(value string) bool {
+	return h.tokens[strings.ToLower(strings.TrimSpace(value))]
+}
+
+// fit learns the replacement values of the mean, median and mode columns from the present cells of records.
+func (h *missingValueHandler) fit(records [][]string) error {
+	if len(records) == 0 {
+		return newInvalidDimensionError("record count", 0, "Can not fit missing value handler to empty records")
+	}
+
+	h.fills = map[int]string{}
+	h.fitted = false
+	for columnIndex := range records[0] {
+		var strategy string = h.policy(columnIndex).strategy
+		if strategy != missingMean && strategy != missingMedian && strategy != missingMode {
+			continue
+		}
+
+		var present []string
+		for _, record := range records {
+			if columnIndex < len(record) && !h.isMissing(record[columnIndex]) {
+				present = append(present, strings.TrimSpace(record[columnIndex]))
+			}
+		}
+
+		if len(present) == 0 {
+			return fmt.Errorf("column %d: no present values to compute the %s from", columnIndex, strategy)
+		}
+
+		if strategy == missingMode {
+			h.fills[columnIndex] = mostFrequent(present)
+			continue
+		}
+
+		var values vector = make(vector, len(present))
+		for index, value := range present {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return fmt.Errorf("column %d: can not compute the %s of non-numeric values: %w", columnIndex, strategy, err)
+			}
+
+			values[index] = parsed
+		}
+
+		var fill float64
+		if strategy == missingMean {
+			fill = vectorSum(values) / float64(len(values))
+		} else {
+			sort.Float64s(values)
+			fill = quantile(values, 0.5)
+		}
+
+		h.fills[columnIndex] = strconv.FormatFloat(fill, 'g', -1, 64)
+	}
+
+	h.fitted = true
+	return nil
+}
+
+// mostFrequent returns the most frequent of values, preferring the smallest value on ties.
+func mostFrequent(values []string) string {
+	var counts map[string]int = map[string]int{}
+	for _, value := range values {
+		counts[value]++
+	}
+
+	var best string
+	var bestCount int = 0
+	for value, count := range counts {
+		if count > bestCount || (count == bestCount && value < best) {
+			best = value
+			bestCount = count
+		}
+	}
+
+	return best
+}
+
+// transform returns records with missing cells replaced, rows dropped and indicator columns appended after the
+// original columns in ascending column order.
+func (h missingValueHandler) transform(records [][]string) ([][]string, missingValueReport, error) {
+	output, _, report, err := h.apply(records)
+	return output, report, err
+}
+
+func (h *missingValueHandler) fitTransform(records [][]string) ([][]string, missingValueReport, error) {
+	if err := h.fit(records); err != nil {
+		return nil, missingValueReport{}, err
+	}
+
+	return h.transform(records)
+}
+
+// apply is transform that also returns the index of the input record every output record came from.
+func (h missingValueHandler) apply(records [][]string) ([][]string, []int, missingValueReport, error) {
+	var report missingValueReport = missingValueReport{rows: len(records), missingByColumn: map[int]int{}}
+	var output [][]string = make([][]string, 0, len(records))
+	var kept []int = make([]int, 0, len(records))
+
+	var indicatorColumns []int
+	if len(records) != 0 {
+		for columnIndex := range records[0] {
+			if h.policy(columnIndex).strategy == missingIndicator {
+				indicatorColumns = append(indicatorColumns, columnIndex)
+			}
+		}
+	}
+
+	for rowIndex, record := range records {
+		if len(records[0]) != len(record) {
+			return nil, nil, report, newShapeMismatchError(len(records[0]), len(record), "row %d has %d columns but the first row has %d", rowIndex, len(record), len(records[0]))
+		}
+
+		var transformed []string = make([]string, len(record), len(record)+len(indicatorColumns))
+		var drop bool = false
+		var imputed int = 0
+		var missingBefore int = report.missingCells
+
+		for columnIndex, value := range record {
+			if !h.isMissing(value) {
+				transformed[columnIndex] = value
+				continue
+			}
+
+			report.missingCells++
+			report.missingByColumn[columnIndex]++
+
+			var policy missingValuePolicy = h.policy(columnIndex)
+			switch policy.strategy {
+			case missingFail:
+				return nil, nil, report, fmt.Errorf("row %d: column %d: missing value %q", rowIndex, columnIndex, value)
+			case missingDropRow:
+				drop = true
+			case missingMean, missingMedian, missingMode:
+				fill, ok := h.fills[columnIndex]
+				if !ok {
+					return nil, nil, report, fmt.Errorf("column %d: missing value handler has not been fitted. Can not impute the %s", columnIndex, policy.strategy)
+				}
+
+				transformed[columnIndex] = fill
+				imputed++
+			case missingConstant:
+				transformed[columnIndex] = policy.value
+				imputed++
+			case missingIndicator:
+				transformed[columnIndex] = policy.value
+				if policy.value == "" {
+					transformed[columnIndex] = "0"
+				}
+				imputed++
+			}
+		}
+
+		if report.missingCells > missingBefore {
+			report.rowsWithMissing++
+		}
+
+		if drop {
+			report.droppedRows++
+			continue
+		}
+
+		for _, columnIndex := range indicatorColumns {
+			if h.isMissing(record[columnIndex]) {
+				transformed = append(transformed, "1")
+			} else {
+				transformed = append(transformed, "0")
+			}
+		}
+
+		report.imputedCells += imputed
+		output = append(output, transformed)
+		kept = append(kept, rowIndex)
+	}
+
+	return output, kept, report, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// missingTestRecords has missing values in every column, written as different tokens.
+func missingTestRecords() [][]string {
+	return [][]string{
+		{"1", "red", "10", "a"},
+		{"", "red", "NaN", "b"},
+		{"3", "NA", "30", "?"},
+		{"8", "blue", "n/a", "c"},
+	}
+}
+
+func TestMissingValueHandlerImputation(t *testing.T) {
+	var handler *missingValueHandler = newMissingValueHandler(missingValuePolicy{strategy: missingFail}, "?")
+	handler.setPolicy(0, missingValuePolicy{strategy: missingMean})
+	handler.setPolicy(1, missingValuePolicy{strategy: missingMode})
+	handler.setPolicy(2, missingValuePolicy{strategy: missingMedian})
+	handler.setPolicy(3, missingValuePolicy{strategy: missingConstant, value: "unknown"})
+
+	records, report, err := handler.fitTransform(missingTestRecords())
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"1", "red", "10", "a"},
+		{"4", "red", "20", "b"},
+		{"3", "red", "30", "unknown"},
+		{"8", "blue", "20", "c"},
+	}, records)
+	assert.Equal(t, missingValueReport{rows: 4, rowsWithMissing: 3, missingCells: 5, imputedCells: 5, missingByColumn: map[int]int{0: 1, 1: 1, 2: 2, 3: 1}}, report)
+	assert.Equal(t, "3 of 4 rows had missing values, 5 missing cells, 5 imputed cells, 0 dropped rows (column 0: 1, column 1: 1, column 2: 2, column 3: 1)", report.String())
+
+	records, _, err = handler.transform([][]string{{"null", "NA", "", "?"}})
+	require.NoError(t, err)
+	assert.Equal(t, [][]string{{"4", "red", "20", "unknown"}}, records, "Transform should reuse the fitted statistics")
+}
+
+func TestMissingValueHandlerDropAndIndicator(t *testing.T) {
+	var handler *missingValueHandler = newMissingValueHandler(missingValuePolicy{strategy: missingIndicator}, "?")
+	handler.setPolicy(1, missingValuePolicy{strategy: missingDropRow})
+	handler.setPolicy(3, missingValuePolicy{strategy: missingIndicator, value: "none"})
+
+	records, kept, report, err := handler.apply(missingTestRecords())
+	require.NoError(t, err)
+
+	assert.Equal(t, [][]string{
+		{"1", "red", "10", "a", "0", "0", "0"},
+		{"0", "red", "0", "b", "1", "1", "0"},
+		{"8", "blue", "0", "c", "0", "1", "0"},
+	}, records, "Indicator columns should be appended in column order")
+	assert.Equal(t, []int{0, 1, 3}, kept)
+	assert.Equal(t, 1, report.droppedRows)
+	assert.Equal(t, 3, report.imputedCells, "Cells of dropped rows are not imputed")
+	assert.Equal(t, 5, report.missingCells)
+}
+
+func TestMissingValueHandlerErrors(t *testing.T) {
+	var strict *missingValueHandler = newMissingValueHandler(missingValuePolicy{strategy: missingFail})
+	_, _, err := strict.fitTransform(missingTestRecords())
+	assert.EqualError(t, err, `row 1: column 0: missing value ""`)
+
+	var mean *missingValueHandler = newMissingValueHandler(missingValuePolicy{strategy: missingMean})
+	assert.Error(t, mean.fit([][]string{{"red"}}), "Mean imputation needs numeric values")
+	assert.Error(t, mean.fit([][]string{{"NA"}, {""}}), "Imputation needs present values")
+	_, _, err = mean.transform([][]string{{"NA"}})
+	assert.Error(t, err, "Imputation needs a fitted handler")
+
+	var invalidErr *invalidDimensionError
+	assert.True(t, errors.As(mean.fit(nil), &invalidErr))
+
+	var shapeErr *shapeMismatchError
+	_, _, err = mean.transform([][]string{{"1"}, {"1", "2"}})
+	assert.True(t, errors.As(err, &shapeErr), "Ragged records should be rejected")
+
+	assert.Panics(t, func() { newMissingValueHandler(missingValuePolicy{strategy: "interpolate"}) }, "Should panic with unknown strategy")
+	assert.Panics(t, func() { strict.setPolicy(0, missingValuePolicy{}) }, "Should panic with empty strategy")
+}
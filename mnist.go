@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+const mnistClassCount int = 10
+
+// mnistOptions configures trainMNIST.
+type mnistOptions struct {
+	dir          string
+	epochs       int
+	batchSize    int
+	hiddenSize   int
+	learningRate float64
+	momentum     float64
+	seed         int64
+	savePath     string
+}
+
+// runMNIST implements `lnet mnist -dir path [-epochs 5] [-save model.json]`.
+func runMNIST(args []string) error {
+	var flags *flag.FlagSet = flag.NewFlagSet("mnist", flag.ContinueOnError)
+	var options mnistOptions
+	flags.StringVar(&options.dir, "dir", "", "directory with the MNIST IDX files, plain or gzip-compressed")
+	flags.IntVar(&options.epochs, "epochs", 5, "number of epochs to train")
+	flags.IntVar(&options.batchSize, "batch", 64, "batch size")
+	flags.IntVar(&options.hiddenSize, "hidden", 128, "width of the hidden layer")
+	flags.Float64Var(&options.learningRate, "lr", 0.1, "learning rate")
+	flags.Float64Var(&options.momentum, "momentum", 0.9, "momentum")
+	flags.Int64Var(&options.seed, "seed", 1, "seed for initialization and shuffling")
+	flags.StringVar(&options.savePath, "save", "", "path to save the trained network to")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if options.dir == "" {
+		return errors.New("mnist requires -dir")
+	}
+
+	_, err := trainMNIST(options, os.Stdout)
+	return err
+}
+
+// findIDXFile returns the path of name or name.gz in dir.
+func findIDXFile(dir, name string) (string, error) {
+	for _, candidate := range []string{name, name + ".gz"} {
+		var path string = filepath.Join(dir, candidate)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+
+	return "", fmt.Errorf("neither %s nor %s.gz found in %s", name, name, dir)
+}
+
+func loadMNISTSplit(dir, prefix string) (matrix, []int, error) {
+	imagesPath, err := findIDXFile(dir, prefix+"-images-idx3-ubyte")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	labelsPath, err := findIDXFile(dir, prefix+"-labels-idx1-ubyte")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return loadIDXDataset(imagesPath, labelsPath)
+}
+
+// newMNISTNetwork is a single hidden layer classifier for flattened images.
+func newMNISTNetwork(inputCount, hiddenSize int, random *rand.Rand) network {
+	var net network = newDenseClassifier(inputCount, hiddenSize, mnistClassCount, random)
+	for class := 0; class < mnistClassCount; class++ {
+		net.labels = append(net.labels, strconv.Itoa(class))
+	}
+
+	return net
+}
+
+// trainMNIST trains on the train split in options.dir, validates on the t10k split after every epoch and returns
+// the final test accuracy.
+func trainMNIST(options mnistOptions, output io.Writer) (float64, error) {
+	trainInputs, trainTargets, err := loadMNISTSplit(options.dir, "train")
+	if err != nil {
+		return 0, err
+	}
+
+	testInputs, testTargets, err := loadMNISTSplit(options.dir, "t10k")
+	if err != nil {
+		return 0, err
+	}
+
+	if len(trainInputs) == 0 || len(testInputs) == 0 {
+		return 0, errors.New("MNIST splits must not be empty")
+	}
+
+	for _, targets := range [][]int{trainTargets, testTargets} {
+		for index, target := range targets {
+			if target >= mnistClassCount {
+				return 0, &targetOutOfRangeError{sample: index, target: target, classCount: mnistClassCount, message: fmt.Sprintf("MNIST label %d of sample %d is not a digit", target, index)}
+			}
+		}
+	}
+
+	var net network = newMNISTNetwork(len(trainInputs[0]), options.hiddenSize, rand.New(newSplitMix64Source(options.seed)))
+
+	var t trainer = newTrainer(&net, trainingConfig{
+		epochs:            options.epochs,
+		batchSize:         options.batchSize,
+		learningRateStart: options.learningRate,
+		momentum:          options.momentum,
+		shuffle:           true,
+		seed:              options.seed,
+	}, newLoggingCallback(output, 1))
+	t.setValidationData(testInputs, testTargets)
+
+	if err = t.train(trainInputs, trainTargets); err != nil {
+		return 0, err
+	}
+
+	var accuracy float64 = calculateAccuracy(net.predict(testInputs), testTargets)
+	fmt.Fprintf(output, "test accuracy: %.4f\n", accuracy)
+
+	if options.savePath != "" {
+		if err = saveNetwork(&net, options.savePath); err != nil {
+			return 0, err
+		}
+	}
+
+	return accuracy, nil
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// network chains components and finishes with a loss function. activations holds the output of every component
+// from the last forward pass; lastOutput is the output of the final component. labels optionally names the
+// classes by output index.
+type network struct {
+	components  []component
+	loss        lossFunction
+	labels      []string
+	activations []matrix
+	lastOutput  matrix
+}
+
+// newDenseClassifier builds a dense layer, relu, dense layer and softmax network with crossentropy loss, drawing
+// the initial parameters from random.
+func newDenseClassifier(inputCount, hiddenSize, classCount int, random *rand.Rand) network {
+	var hidden denseLayer = newDenseLayerWithRandom(hiddenSize, inputCount, random)
+	var output denseLayer = newDenseLayerWithRandom(classCount, hiddenSize, random)
+	return newNetwork(&crossentropy{}, &hidden, &reluActivation{}, &output, &softmax{})
+}
+
+func newNetwork(loss lossFunction, components ...component) network {
+	if len(components) == 0 {
+		panic("Can not create network with 0 components")
+	}
+
+	if loss == nil {
+		panic("Can not create network without a loss function")
+	}
+
+	return network{components: components, loss: loss}
+}
+
+// forward runs the input through every component and the loss, returning the average loss of the batch.
+func (n *network) forward(input matrix, targets []int) float64 {
+	var output matrix = input
+	var activations []matrix = make([]matrix, len(n.components))
+
+	for index, c := range n.components {
+		output = c.forward(output)
+		activations[index] = output
+	}
+
+	n.activations = activations
+	n.lastOutput = output
+	n.loss.forward(output, targets)
+	return n.loss.calculateAverageLoss()
+}
+
+// predict runs the input through every component without storing any state, so unlike forward it can be called
+// from many goroutines at once. It must not run at the same time as training, which changes the parameters.
+func (n *network) predict(input matrix) matrix {
+	var output matrix = input
+
+	for _, c := range n.components {
+		output = c.predict(output)
+	}
+
+	return output
+}
+
+// predictClasses returns the index of the highest output of every sample.
+func (n *network) predictClasses(input matrix) []int {
+	var output matrix = n.predict(input)
+	var classes []int = make([]int, len(output))
+
+	for index, row := range output {
+		classes[index] = argmax(row)
+	}
+
+	return classes
+}
+
+func (n *network) backward() {
+	n.loss.backward()
+	var forwardInputDerivatives matrix = n.loss.getInputDerivatives()
+
+	for index := len(n.components) - 1; index >= 0; index-- {
+		var c component = n.components[index]
+		c.backward(forwardInputDerivatives)
+		forwardInputDerivatives = c.getInputDerivatives()
+	}
+}
+
+func (n *network) getInputDerivatives() matrix {
+	return n.components[0].getInputDerivatives()
+}
+
+func (n *network) trainables() []trainable {
+	var trainables []trainable
+
+	for _, c := range n.components {
+		if t, ok := c.(trainable); ok {
+			trainables = append(trainables, t)
+		}
+	}
+
+	return trainables
+}
+
+// checkComponentWidths returns a shapeMismatchError if a layer, dense layer or fitted scaler follows a component
+// whose output has a different width, which would otherwise only panic on the first forward pass. Activations keep
+// the width of their input; after any other component the width is unknown and not checked.
+func (n *network) checkComponentWidths() error {
+	var width int = 0
+	for index, c := range n.components {
+		var inputCount, outputCount int
+
+		switch typed := c.(type) {
+		case *layer:
+			inputCount, outputCount = typed.inputCount, typed.layerSize
+		case *denseLayer:
+			inputCount, outputCount = typed.inputCount, typed.layerSize
+		case *featureScaler:
+			if typed.centers == nil {
+				continue
+			}
+			inputCount, outputCount = len(typed.centers), len(typed.centers)
+		case *reluActivation, *softmax, *logSoftmax:
+			continue
+		default:
+			width = 0
+			continue
+		}
+
+		if width != 0 && inputCount != width {
+			return newShapeMismatchError(inputCount, width, "%s expects %d inputs but the component before it outputs %d", n.describeComponent(index), inputCount, width)
+		}
+
+		width = outputCount
+	}
+
+	return nil
+}
+
+// describeComponent names a component by its position and type, e.g. "component[2] (*main.layer)".
+func (n *network) describeComponent(index int) string {
+	return fmt.Sprintf("component[%d] (%T)", index, n.components[index])
+}
+
+// snapshotParameters copies the parameters of every trainable component of n.
+func snapshotParameters(n *network) []vector {
+	var trainables []trainable = n.trainables()
+	var snapshot []vector = make([]vector, len(trainables))
+
+	for index, t := range trainables {
+		snapshot[index] = t.getParameters()
+	}
+
+	return snapshot
+}
+
+func restoreParameters(n *network, snapshot []vector) {
+	var trainables []trainable = n.trainables()
+
+	if len(snapshot) != len(trainables) {
+		panic(fmt.Sprintf("Snapshot of %d trainable components does not match network with %d trainable components", len(snapshot), len(trainables)))
+	}
+
+	for index, t := range trainables {
+		t.setParameters(snapshot[index])
+	}
+}
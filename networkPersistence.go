@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+const (
+	componentTypeLayer      string = "layer"
+	componentTypeDenseLayer string = "denseLayer"
+	componentTypeRelu       string = "relu"
+	componentTypeSoftmax    string = "softmax"
+	componentTypeLogSoftmax string = "logSoftmax"
+	componentTypeScaler     string = "scaler"
+	lossTypeCrossentropy    string = "crossentropy"
+	lossTypeNLL             string = "nll"
+)
+
+type componentSpec struct {
+	Type    string `json:"type"`
+	Weights matrix `json:"weights,omitempty"`
+	Biases  vector `json:"biases,omitempty"`
+	Kind    string `json:"kind,omitempty"`
+	Centers vector `json:"centers,omitempty"`
+	Scales  vector `json:"scales,omitempty"`
+}
+
+// networkSpec is the serialized form of a network: its architecture, every weight and bias and the class labels.
+type networkSpec struct {
+	Components []componentSpec `json:"components"`
+	Loss       string          `json:"loss"`
+	Labels     []string        `json:"labels,omitempty"`
+}
+
+func newComponentSpec(c component) (componentSpec, error) {
+	switch typed := c.(type) {
+	case *layer:
+		var weights matrix = make(matrix, len(typed.neurons))
+		var biases vector = make(vector, len(typed.neurons))
+		for index, n := range typed.neurons {
+			weights[index] = append(vector{}, n.weights...)
+			biases[index] = n.bias
+		}
+
+		return componentSpec{Type: componentTypeLayer, Weights: weights, Biases: biases}, nil
+	case *denseLayer:
+		return componentSpec{Type: componentTypeDenseLayer, Weights: typed.weights.toMatrix(), Biases: append(vector{}, typed.biases...)}, nil
+	case *reluActivation:
+		return componentSpec{Type: componentTypeRelu}, nil
+	case *softmax:
+		return componentSpec{Type: componentTypeSoftmax}, nil
+	case *logSoftmax:
+		return componentSpec{Type: componentTypeLogSoftmax}, nil
+	case *featureScaler:
+		if typed.centers == nil {
+			return componentSpec{}, fmt.Errorf("can not serialize a scaler that has not been fitted")
+		}
+
+		return componentSpec{Type: componentTypeScaler, Kind: typed.kind, Centers: typed.centers, Scales: typed.scales}, nil
+	}
+
+	return componentSpec{}, fmt.Errorf("can not serialize component of type %T", c)
+}
+
+func (s componentSpec) validateWeights() error {
+	if len(s.Weights) == 0 || len(s.Weights) != len(s.Biases) {
+		return fmt.Errorf("%s has %d weight rows and %d biases", s.Type, len(s.Weights), len(s.Biases))
+	}
+
+	for _, row := range s.Weights {
+		if len(row) == 0 || len(row) != len(s.Weights[0]) {
+			return fmt.Errorf("%s has weight rows of differing or zero length", s.Type)
+		}
+	}
+
+	return nil
+}
+
+func (s componentSpec) build() (component, error) {
+	switch s.Type {
+	case componentTypeLayer:
+		if err := s.validateWeights(); err != nil {
+			return nil, err
+		}
+
+		var l layer = newLayerExplicit(s.Weights, s.Biases)
+		return &l, nil
+	case componentTypeDenseLayer:
+		if err := s.validateWeights(); err != nil {
+			return nil, err
+		}
+
+		var l denseLayer = newDenseLayerExplicit(s.Weights, s.Biases)
+		return &l, nil
+	case componentTypeRelu:
+		return &reluActivation{}, nil
+	case componentTypeSoftmax:
+		return &softmax{}, nil
+	case componentTypeLogSoftmax:
+		return &logSoftmax{}, nil
+	case componentTypeScaler:
+		scaler, err := tryNewFeatureScalerExplicit(s.Kind, s.Centers, s.Scales)
+		if err != nil {
+			return nil, err
+		}
+
+		return scaler, nil
+	}
+
+	return nil, fmt.Errorf("unknown component type %q", s.Type)
+}
+
+func newNetworkSpec(n *network) (networkSpec, error) {
+	var spec networkSpec = networkSpec{Labels: n.labels}
+
+	for index, c := range n.components {
+		componentSpec, err := newComponentSpec(c)
+		if err != nil {
+			return networkSpec{}, fmt.Errorf("%s: %w", n.describeComponent(index), err)
+		}
+
+		spec.Components = append(spec.Components, componentSpec)
+	}
+
+	switch n.loss.(type) {
+	case *crossentropy:
+		spec.Loss = lossTypeCrossentropy
+	case *negativeLogLikelihood:
+		spec.Loss = lossTypeNLL
+	default:
+		return networkSpec{}, fmt.Errorf("can not serialize loss of type %T", n.loss)
+	}
+
+	return spec, nil
+}
+
+func (s networkSpec) build() (network, error) {
+	var components []component = make([]component, len(s.Components))
+
+	for index, componentSpec := range s.Components {
+		c, err := componentSpec.build()
+		if err != nil {
+			return network{}, fmt.Errorf("component[%d]: %w", index, err)
+		}
+
+		components[index] = c
+	}
+
+	if len(components) == 0 {
+		return network{}, fmt.Errorf("network has no components")
+	}
+
+	var loss lossFunction
+	switch s.Loss {
+	case lossTypeCrossentropy:
+		loss = &crossentropy{}
+	case lossTypeNLL:
+		loss = &negativeLogLikelihood{}
+	default:
+		return network{}, fmt.Errorf("unknown loss type %q", s.Loss)
+	}
+
+	var n network = newNetwork(loss, components...)
+	if err := n.checkComponentWidths(); err != nil {
+		return network{}, err
+	}
+
+	n.labels = s.Labels
+	return n, nil
+}
+
+func saveNetwork(n *network, path string) error {
+	spec, err := newNetworkSpec(n)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(spec)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, data)
+}
+
+// writeFileAtomic writes data next to path and renames it into place, so readers never see a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	var temporaryPath string = path + ".tmp"
+
+	if err := ioutil.WriteFile(temporaryPath, data, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(temporaryPath, path)
+}
+
+func loadNetwork(path string) (network, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return network{}, err
+	}
+
+	var spec networkSpec
+	if err = json.Unmarshal(data, &spec); err != nil {
+		return network{}, fmt.Errorf("can not parse network file %s: %w", path, err)
+	}
+
+	return spec.build()
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveAndLoadNetwork(t *testing.T) {
+	rand.Seed(1)
+	var original network = newTestNetwork()
+	var path string = filepath.Join(t.TempDir(), "model.json")
+	var inputs matrix = randomMatrix(4, 4)
+	var targets []int = []int{0, 1, 2, 0}
+
+	require.NoError(t, saveNetwork(&original, path))
+
+	loaded, err := loadNetwork(path)
+	require.NoError(t, err)
+	require.Len(t, loaded.components, len(original.components), "Loaded network has the wrong number of components")
+	require.Equal(t, original.forward(inputs, targets), loaded.forward(inputs, targets), "Loaded network should produce the same loss")
+	require.Equal(t, original.lastOutput, loaded.lastOutput, "Loaded network should produce the same output")
+}
+
+func TestSaveAndLoadLogSoftmaxNetwork(t *testing.T) {
+	rand.Seed(2)
+	var l denseLayer = newDenseLayer(3, 4)
+	var original network = newNetwork(&negativeLogLikelihood{}, &l, &logSoftmax{})
+	var path string = filepath.Join(t.TempDir(), "model.json")
+	var inputs matrix = randomMatrix(4, 4)
+	var targets []int = []int{0, 1, 2, 0}
+
+	require.NoError(t, saveNetwork(&original, path))
+
+	loaded, err := loadNetwork(path)
+	require.NoError(t, err)
+	require.IsType(t, &logSoftmax{}, loaded.components[1])
+	require.IsType(t, &negativeLogLikelihood{}, loaded.loss)
+	require.Equal(t, original.forward(inputs, targets), loaded.forward(inputs, targets), "Loaded network should produce the same loss")
+}
+
+func TestSaveNetworkRejectsUnknownComponents(t *testing.T) {
+	var e expressionComponent = newExpressionComponent(reluExpression)
+	var n network = newNetwork(&crossentropy{}, &e)
+
+	require.Error(t, saveNetwork(&n, filepath.Join(t.TempDir(), "model.json")), "Expression components can not be serialized")
+}
+
+func TestLoadNetworkErrors(t *testing.T) {
+	var directory string = t.TempDir()
+	var files map[string]string = map[string]string{
+		"invalid json":      `{`,
+		"no components":     `{"components": [], "loss": "crossentropy"}`,
+		"unknown component": `{"components": [{"type": "conv"}], "loss": "crossentropy"}`,
+		"unknown loss":      `{"components": [{"type": "relu"}], "loss": "mse"}`,
+		"missing biases":    `{"components": [{"type": "layer", "weights": [[1, 2]]}], "loss": "crossentropy"}`,
+		"ragged weights":    `{"components": [{"type": "denseLayer", "weights": [[1, 2], [3]], "biases": [1, 2]}], "loss": "crossentropy"}`,
+		"unknown scaler":    `{"components": [{"type": "scaler", "kind": "log", "centers": [1], "scales": [1]}], "loss": "crossentropy"}`,
+		"missing scales":    `{"components": [{"type": "scaler", "kind": "standard", "centers": [1]}], "loss": "crossentropy"}`,
+		"zero scale":        `{"components": [{"type": "scaler", "kind": "standard", "centers": [1], "scales": [0]}], "loss": "crossentropy"}`,
+		"mismatched widths": `{"components": [{"type": "denseLayer", "weights": [[1, 2]], "biases": [0]}, {"type": "relu"}, {"type": "denseLayer", "weights": [[1, 2, 3]], "biases": [0]}], "loss": "crossentropy"}`,
+		"scaler width":      `{"components": [{"type": "scaler", "kind": "standard", "centers": [0, 0, 0], "scales": [1, 1, 1]}, {"type": "layer", "weights": [[1, 2]], "biases": [0]}], "loss": "crossentropy"}`,
+	}
+
+	_, err := loadNetwork(filepath.Join(directory, "missing.json"))
+	require.Error(t, err, "Loading a missing file should fail")
+
+	for name, contents := range files {
+		var path string = filepath.Join(directory, "model.json")
+		require.NoError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+
+		_, err := loadNetwork(path)
+		require.Error(t, err, name)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestNetwork() network {
+	var l1 layer = newLayer(5, 4)
+	var l2 denseLayer = newDenseLayer(3, 5)
+
+	return newNetwork(&crossentropy{}, &l1, &reluActivation{}, &l2, &softmax{})
+}
+
+func TestNewNetworkPanics(t *testing.T) {
+	var l layer = newLayer(1, 1)
+
+	assert.Panics(t, func() { newNetwork(&crossentropy{}) }, "Should panic with 0 components")
+	assert.Panics(t, func() { newNetwork(nil, &l) }, "Should panic without a loss function")
+}
+
+func TestNetworkTrainables(t *testing.T) {
+	var n network = newTestNetwork()
+
+	require.Len(t, n.trainables(), 2, "Network should expose both layers as trainables")
+	require.Equal(t, "component[1] (*main.reluActivation)", n.describeComponent(1), "Wrong component description")
+}
+
+func TestNetworkGradientCheck(t *testing.T) {
+	rand.Seed(9)
+	var n network = newTestNetwork()
+	var inputs matrix = randomMatrix(6, 4)
+	var targets []int = []int{0, 1, 2, 2, 1, 0}
+
+	var report gradientCheckReport = checkNetworkGradients(&n, inputs, targets, defaultGradientCheckEpsilon)
+
+	require.Len(t, report.entries, 5*5+3*6+6*4, "Gradient check should cover every parameter and input")
+	require.Empty(t, report.failures(1e-5), report.String())
+}
+
+func TestNetworkPredictMatchesForward(t *testing.T) {
+	rand.Seed(12)
+	var n network = newTestNetwork()
+	var inputs matrix = randomMatrix(5, 4)
+	var targets []int = []int{0, 1, 2, 1, 0}
+
+	var predicted matrix = n.predict(inputs)
+	require.Nil(t, n.components[0].(*layer).lastInput, "Predict should not store the input")
+	require.Nil(t, n.components[3].(*softmax).lastOutput, "Predict should not store the output")
+
+	n.forward(inputs, targets)
+	require.Equal(t, n.lastOutput, predicted, "Predict should produce the same output as forward")
+
+	var classes []int = n.predictClasses(inputs)
+	require.Len(t, classes, len(inputs))
+	for index, class := range classes {
+		require.Equal(t, argmax(predicted[index]), class)
+	}
+}
+
+func TestNetworkPredictConcurrently(t *testing.T) {
+	rand.Seed(13)
+	var l1 layer = newLayer(8, 4)
+	var l2 denseLayer = newDenseLayer(6, 8)
+	var l3 expressionComponent = newDenseExpressionComponent(randomMatrix(3, 6), vector{0.1, 0.2, 0.3})
+	var n network = newNetwork(&negativeLogLikelihood{}, &l1, &reluActivation{}, &l2, &reluActivation{}, &l3, &logSoftmax{})
+
+	const goroutines int = 16
+	var inputs []matrix = make([]matrix, goroutines)
+	var expected []matrix = make([]matrix, goroutines)
+	for index := range inputs {
+		inputs[index] = randomMatrix(index+1, 4)
+		expected[index] = n.predict(inputs[index])
+	}
+
+	var waitGroup sync.WaitGroup
+	var results []matrix = make([]matrix, goroutines)
+	var classes [][]int = make([][]int, goroutines)
+
+	for index := 0; index < goroutines; index++ {
+		waitGroup.Add(1)
+		go func(index int) {
+			defer waitGroup.Done()
+			for repeat := 0; repeat < 20; repeat++ {
+				results[index] = n.predict(inputs[index])
+				classes[index] = n.predictClasses(inputs[index])
+			}
+		}(index)
+	}
+
+	waitGroup.Wait()
+
+	for index := range results {
+		require.Equal(t, expected[index], results[index], "Concurrent predictions should match sequential predictions")
+		require.Len(t, classes[index], index+1)
+	}
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// numericHealthError reports the first non-finite value found in a training step. quantity is what was checked,
+// location the component or loss it belongs to and element the sample and neuron or parameter within it.
+type numericHealthError struct {
+	quantity string
+	location string
+	element  string
+	value    float64
+	epoch    int
+	batch    int
+}
+
+func (e *numericHealthError) Error() string {
+	var message string = fmt.Sprintf("non-finite %s %v in %s", e.quantity, e.value, e.location)
+	if e.element != "" {
+		message += " at " + e.element
+	}
+
+	return fmt.Sprintf("%s during epoch %d batch %d", message, e.epoch, e.batch)
+}
+
+func isFinite(value float64) bool {
+	return !math.IsNaN(value) && !math.IsInf(value, 0)
+}
+
+// firstNonFinite returns the sample and column of the first non-finite value in m.
+func firstNonFinite(m matrix) (int, int, bool) {
+	for rowIndex, row := range m {
+		for columnIndex, value := range row {
+			if !isFinite(value) {
+				return rowIndex, columnIndex, true
+			}
+		}
+	}
+
+	return 0, 0, false
+}
+
+func describeLoss(n *network) string {
+	return fmt.Sprintf("loss (%T)", n.loss)
+}
+
+// checkForwardHealth checks the output of every component in order, then the average loss of the batch.
+func checkForwardHealth(n *network, loss float64) *numericHealthError {
+	for index, activation := range n.activations {
+		if sample, column, found := firstNonFinite(activation); found {
+			return &numericHealthError{
+				quantity: "activation",
+				location: n.describeComponent(index),
+				element:  fmt.Sprintf("sample %d neuron %d", sample, column),
+				value:    activation[sample][column],
+			}
+		}
+	}
+
+	if !isFinite(loss) {
+		return &numericHealthError{quantity: "loss", location: describeLoss(n), value: loss}
+	}
+
+	return nil
+}
+
+// checkBackwardHealth follows the backward pass from the loss to the first component and checks the input
+// derivatives of each step, then the parameter gradients of every trainable component.
+func checkBackwardHealth(n *network) *numericHealthError {
+	var lossDerivatives matrix = n.loss.getInputDerivatives()
+	if sample, column, found := firstNonFinite(lossDerivatives); found {
+		return &numericHealthError{
+			quantity: "input derivative",
+			location: describeLoss(n),
+			element:  fmt.Sprintf("sample %d input %d", sample, column),
+			value:    lossDerivatives[sample][column],
+		}
+	}
+
+	for index := len(n.components) - 1; index >= 0; index-- {
+		var inputDerivatives matrix = n.components[index].getInputDerivatives()
+		if sample, column, found := firstNonFinite(inputDerivatives); found {
+			return &numericHealthError{
+				quantity: "input derivative",
+				location: n.describeComponent(index),
+				element:  fmt.Sprintf("sample %d input %d", sample, column),
+				value:    inputDerivatives[sample][column],
+			}
+		}
+	}
+
+	return checkTrainableHealth(n, "parameter gradient", trainable.getParameterGradients)
+}
+
+// checkParameterHealth checks the parameters of every trainable component.
+func checkParameterHealth(n *network) *numericHealthError {
+	return checkTrainableHealth(n, "parameter", trainable.getParameters)
+}
+
+func checkTrainableHealth(n *network, quantity string, values func(t trainable) vector) *numericHealthError {
+	for index, c := range n.components {
+		t, ok := c.(trainable)
+		if !ok {
+			continue
+		}
+
+		for parameterIndex, value := range values(t) {
+			if !isFinite(value) {
+				return &numericHealthError{
+					quantity: quantity,
+					location: n.describeComponent(index),
+					element:  t.describeParameter(parameterIndex),
+					value:    value,
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func requireNumericHealthError(t *testing.T, err error) *numericHealthError {
+	var healthErr *numericHealthError
+	require.Error(t, err)
+	require.True(t, errors.As(err, &healthErr), "Should fail with a numericHealthError, got %v", err)
+	return healthErr
+}
+
+func TestNumericHealthDetectsActivation(t *testing.T) {
+	var l1 layer = newLayerExplicit(matrix{{1}, {1}}, vector{0, 0})
+	var l2 layer = newLayerExplicit(matrix{{1, 0}, {math.Inf(1), 0}}, vector{0, 0})
+	var net network = newNetwork(&crossentropy{}, &l1, &reluActivation{}, &l2, &softmax{})
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 1, learningRateStart: 1, checkNumericHealth: true})
+
+	var healthErr *numericHealthError = requireNumericHealthError(t, tr.train(matrix{{1}}, []int{0}))
+	assert.Equal(t, "activation", healthErr.quantity)
+	assert.Equal(t, "component[2] (*main.layer)", healthErr.location, "Should report the first component with a non-finite output")
+	assert.Equal(t, "sample 0 neuron 1", healthErr.element)
+	assert.Equal(t, 1, healthErr.epoch)
+	assert.Equal(t, 1, healthErr.batch)
+	assert.Equal(t, "non-finite activation +Inf in component[2] (*main.layer) at sample 0 neuron 1 during epoch 1 batch 1", healthErr.Error())
+}
+
+func TestNumericHealthDetectsLossDerivative(t *testing.T) {
+	var l layer = newLayerExplicit(matrix{{0}, {-800}}, vector{0, 0})
+	var net network = newNetwork(&crossentropy{}, &l, &softmax{})
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 1, learningRateStart: 1, checkNumericHealth: true})
+
+	var healthErr *numericHealthError = requireNumericHealthError(t, tr.train(matrix{{1}}, []int{1}))
+	assert.Equal(t, "input derivative", healthErr.quantity)
+	assert.Equal(t, "loss (*main.crossentropy)", healthErr.location)
+	assert.Equal(t, "sample 0 input 1", healthErr.element)
+	assert.True(t, math.IsInf(healthErr.value, -1))
+}
+
+func TestNumericHealthDetectsParameter(t *testing.T) {
+	var l layer = newLayerExplicit(matrix{{0}, {0}}, vector{0, 0})
+	var net network = newNetwork(&crossentropy{}, &l, &softmax{})
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 1, learningRateStart: math.MaxFloat64, checkNumericHealth: true})
+
+	var healthErr *numericHealthError = requireNumericHealthError(t, tr.train(matrix{{10}}, []int{0}))
+	assert.Equal(t, "parameter", healthErr.quantity)
+	assert.Equal(t, "component[0] (*main.layer)", healthErr.location)
+	assert.Equal(t, "neuron[0].weights[0]", healthErr.element)
+}
+
+func TestNumericHealthDetectsParameterGradient(t *testing.T) {
+	var l layer = newLayerExplicit(matrix{{0}, {0}}, vector{0, 0})
+	var net network = newNetwork(&crossentropy{}, &l, &softmax{})
+	net.forward(matrix{{1}}, []int{0})
+	net.backward()
+	l.neurons[1].derivativeBias = math.NaN()
+
+	var healthErr *numericHealthError = checkBackwardHealth(&net)
+	require.NotNil(t, healthErr)
+	assert.Equal(t, "parameter gradient", healthErr.quantity)
+	assert.Equal(t, "neuron[1].bias", healthErr.element)
+}
+
+func TestNumericHealthDisabledIgnoresNonFiniteValues(t *testing.T) {
+	var l layer = newLayerExplicit(matrix{{0}, {0}}, vector{0, 0})
+	var net network = newNetwork(&crossentropy{}, &l, &softmax{})
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 1, learningRateStart: math.MaxFloat64})
+
+	require.NoError(t, tr.train(matrix{{10}}, []int{0}), "Should only check numeric health when enabled")
+}
+
+func TestNumericHealthDoesNotChangeHealthyTraining(t *testing.T) {
+	var inputs, targets = trainingTestData()
+
+	var unchecked network = newTrainingTestNetwork(8)
+	var uncheckedHistory *lossHistoryCallback = &lossHistoryCallback{}
+	var uncheckedTrainer trainer = newTrainer(&unchecked, trainingConfig{epochs: 20, learningRateStart: 1}, uncheckedHistory)
+	require.NoError(t, uncheckedTrainer.train(inputs, targets))
+
+	var checked network = newTrainingTestNetwork(8)
+	var checkedHistory *lossHistoryCallback = &lossHistoryCallback{}
+	var checkedTrainer trainer = newTrainer(&checked, trainingConfig{epochs: 20, learningRateStart: 1, checkNumericHealth: true}, checkedHistory)
+	require.NoError(t, checkedTrainer.train(inputs, targets))
+
+	assert.Equal(t, uncheckedHistory.epochLosses, checkedHistory.epochLosses)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// workerPool splits independent loop iterations across goroutines. Every iteration is handled by exactly one
+// worker and runs the same code it would run sequentially, so results do not depend on the worker count.
+type workerPool struct {
+	workers int
+}
+
+// minTasksPerWorker is the fewest iterations a worker is started for. Loops over small layers and batches run
+// inline instead of paying for goroutines that would each do almost nothing.
+const minTasksPerWorker int = 16
+
+// computeWorkers is the worker count of the pool used by the layers and activations. It is read from many
+// goroutines, so it is only accessed atomically through computePool and setComputeWorkers.
+var computeWorkers int32 = int32(runtime.NumCPU())
+
+func newWorkerPool(workers int) workerPool {
+	if workers <= 0 {
+		panic(fmt.Sprintf("Can not create worker pool with %d workers", workers))
+	}
+
+	return workerPool{workers: workers}
+}
+
+// computePool returns the pool used by the layers and activations.
+func computePool() workerPool {
+	return workerPool{workers: int(atomic.LoadInt32(&computeWorkers))}
+}
+
+// setComputeWorkers changes the worker count of computePool. It is safe to call from any goroutine, but must not
+// be called while training or predicting, since every loop of a running computation reads the count anew.
+func setComputeWorkers(workers int) {
+	atomic.StoreInt32(&computeWorkers, int32(newWorkerPool(workers).workers))
+}
+
+// run calls task with contiguous [start, end) ranges that together cover [0, taskCount), each of at least
+// minTasksPerWorker iterations unless taskCount is smaller. A panic raised by any worker is re-raised on the calling goroutine once all workers have finished.
+func (p workerPool) run(taskCount int, task func(start, end int)) {
+	if taskCount <= 0 {
+		return
+	}
+
+	var chunkCount int = minInt(p.workers, (taskCount+minTasksPerWorker-1)/minTasksPerWorker)
+	if chunkCount == 1 {
+		task(0, taskCount)
+		return
+	}
+
+	var chunkSize int = (taskCount + chunkCount - 1) / chunkCount
+	var waitGroup sync.WaitGroup
+	var panicMutex sync.Mutex
+	var firstPanic interface{}
+
+	for start := 0; start < taskCount; start += chunkSize {
+		var end int = minInt(start+chunkSize, taskCount)
+		waitGroup.Add(1)
+
+		go func(start, end int) {
+			defer waitGroup.Done()
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					panicMutex.Lock()
+					if firstPanic == nil {
+						firstPanic = recovered
+					}
+					panicMutex.Unlock()
+				}
+			}()
+
+			task(start, end)
+		}(start, end)
+	}
+
+	waitGroup.Wait()
+
+	if firstPanic != nil {
+		panic(firstPanic)
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withComputeWorkers(workers int, f func()) {
+	var previousWorkers int = computePool().workers
+	setComputeWorkers(workers)
+	defer setComputeWorkers(previousWorkers)
+
+	f()
+}
+
+func TestWorkerPoolRunCoversAllTasks(t *testing.T) {
+	for _, workers := range []int{1, 2, 3, 8, 32} {
+		var pool workerPool = newWorkerPool(workers)
+		var visits []int = make([]int, 17*minTasksPerWorker)
+		var mutex sync.Mutex
+
+		pool.run(len(visits), func(start, end int) {
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			for index := start; index < end; index++ {
+				visits[index]++
+			}
+		})
+
+		for index, visitCount := range visits {
+			require.Equal(t, 1, visitCount, fmt.Sprintf("Task %d should run exactly once with %d workers", index, workers))
+		}
+	}
+}
+
+func TestWorkerPoolPanics(t *testing.T) {
+	assert.Panics(t, func() { newWorkerPool(0) }, "Should panic with 0 workers")
+
+	var pool workerPool = newWorkerPool(4)
+	assert.Panics(t, func() {
+		pool.run(4*minTasksPerWorker, func(start, end int) {
+			if start > 0 {
+				panic("worker failure")
+			}
+		})
+	}, "Should re-raise a panic from a worker on the calling goroutine")
+}
+
+func TestWorkerPoolRunsSmallLoopsInline(t *testing.T) {
+	var pool workerPool = newWorkerPool(8)
+	var ranges [][2]int
+	var mutex sync.Mutex
+
+	pool.run(3*minTasksPerWorker-1, func(start, end int) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		ranges = append(ranges, [2]int{start, end})
+	})
+	assert.Len(t, ranges, 3, "Every worker should get at least minTasksPerWorker iterations")
+
+	ranges = nil
+	pool.run(minTasksPerWorker, func(start, end int) { ranges = append(ranges, [2]int{start, end}) })
+	assert.Equal(t, [][2]int{{0, minTasksPerWorker}}, ranges, "A loop of minTasksPerWorker iterations should run inline")
+}
+
+func TestParallelResultsIndependentOfWorkerCount(t *testing.T) {
+	rand.Seed(11)
+	var inputs matrix = randomMatrix(53, 20)
+	var forwardDerivatives matrix = randomMatrix(53, 9)
+	var l layer = newLayer(9, 20)
+	var d denseLayer = newDenseLayerFromLayer(l)
+
+	type results struct {
+		layerOutput            matrix
+		layerInputDerivatives  matrix
+		layerWeightDerivatives matrix
+		denseOutput            matrix
+		denseInputDerivatives  matrix
+		denseWeightDerivatives matrix
+		softmaxDerivatives     matrix
+	}
+
+	var compute func() results = func() results {
+		var r results
+		r.layerOutput = l.forward(inputs)
+		l.backward(forwardDerivatives)
+		r.layerInputDerivatives = l.getInputDerivatives()
+		for _, n := range l.neurons {
+			r.layerWeightDerivatives = append(r.layerWeightDerivatives, n.derivativeWeights)
+		}
+
+		r.denseOutput = d.forward(inputs)
+		d.backward(forwardDerivatives)
+		r.denseInputDerivatives = d.getInputDerivatives()
+		r.denseWeightDerivatives = d.derivativeWeights.toMatrix()
+
+		var s softmax
+		s.forward(r.layerOutput)
+		s.backward(forwardDerivatives)
+		r.softmaxDerivatives = s.getInputDerivatives()
+
+		return r
+	}
+
+	var sequential results
+	withComputeWorkers(1, func() { sequential = compute() })
+
+	for _, workers := range []int{2, 4, 7} {
+		withComputeWorkers(workers, func() {
+			require.Equal(t, sequential, compute(), fmt.Sprintf("Results with %d workers should match sequential results", workers))
+		})
+	}
+}
+
+const parallelBenchmarkBatchSize int = 4096
+
+func benchmarkWorkerCounts() []int {
+	var counts []int = []int{1}
+	if runtime.NumCPU() > 1 {
+		counts = append(counts, runtime.NumCPU())
+	}
+
+	return counts
+}
+
+func BenchmarkParallelLayer(b *testing.B) {
+	var l layer = newLayer(benchmarkLayerSize, benchmarkInputCount)
+	var inputs matrix = randomMatrix(parallelBenchmarkBatchSize, benchmarkInputCount)
+	var forwardDerivatives matrix = randomMatrix(parallelBenchmarkBatchSize, benchmarkLayerSize)
+
+	for _, workers := range benchmarkWorkerCounts() {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			withComputeWorkers(workers, func() {
+				for i := 0; i < b.N; i++ {
+					l.forward(inputs)
+					l.backward(forwardDerivatives)
+					l.getInputDerivatives()
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkParallelDenseLayer(b *testing.B) {
+	var l denseLayer = newDenseLayer(benchmarkLayerSize, benchmarkInputCount)
+	var inputs matrix = randomMatrix(parallelBenchmarkBatchSize, benchmarkInputCount)
+	var forwardDerivatives matrix = randomMatrix(parallelBenchmarkBatchSize, benchmarkLayerSize)
+
+	for _, workers := range benchmarkWorkerCounts() {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			withComputeWorkers(workers, func() {
+				for i := 0; i < b.N; i++ {
+					l.forward(inputs)
+					l.backward(forwardDerivatives)
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkParallelSoftmax(b *testing.B) {
+	var s softmax
+	var inputs matrix = randomMatrix(parallelBenchmarkBatchSize, benchmarkLayerSize)
+	var forwardDerivatives matrix = randomMatrix(parallelBenchmarkBatchSize, benchmarkLayerSize)
+
+	for _, workers := range benchmarkWorkerCounts() {
+		b.Run(fmt.Sprintf("workers=%d", workers), func(b *testing.B) {
+			withComputeWorkers(workers, func() {
+				for i := 0; i < b.N; i++ {
+					s.forward(inputs)
+					s.backward(forwardDerivatives)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// batchPreprocessor transforms a batch before training, for example with a fitted featureScaler. It is called
+// from several goroutines at once and must not modify shared state.
+type batchPreprocessor func(inputs matrix, targets []int) (matrix, []int, error)
+
+// pipelineConfig configures a prefetcher. workers goroutines preprocess batches and at most bufferedBatches
+// batches wait for the consumer on top of the ones being preprocessed. preprocess may be nil.
+type pipelineConfig struct {
+	workers         int
+	bufferedBatches int
+	preprocess      batchPreprocessor
+}
+
+type prefetchResult struct {
+	index   int
+	inputs  matrix
+	targets []int
+	err     error
+}
+
+// prefetcher reads batches from a batchStream on a background goroutine and preprocesses them on worker
+// goroutines while the consumer works on earlier batches. Batches are returned in stream order whatever the
+// worker count, and a semaphore bounds how many batches exist at once, so memory use stays bounded even if
+// one worker is slow.
+type prefetcher struct {
+	ctx       context.Context
+	cancel    context.CancelFunc
+	results   chan prefetchResult
+	tokens    chan struct{}
+	pending   map[int]prefetchResult
+	nextIndex int
+	waitGroup sync.WaitGroup
+}
+
+func newPrefetcher(ctx context.Context, stream *batchStream, config pipelineConfig) *prefetcher {
+	if config.workers <= 0 {
+		panic(fmt.Sprintf("Can not prefetch with %d workers", config.workers))
+	}
+
+	if config.bufferedBatches < 0 {
+		panic(fmt.Sprintf("Can not prefetch %d batches", config.bufferedBatches))
+	}
+
+	var inFlight int = config.workers + config.bufferedBatches
+	ctx, cancel := context.WithCancel(ctx)
+	var p *prefetcher = &prefetcher{
+		ctx:     ctx,
+		cancel:  cancel,
+		results: make(chan prefetchResult, inFlight),
+		tokens:  make(chan struct{}, inFlight),
+		pending: map[int]prefetchResult{},
+	}
+
+	var jobs chan prefetchResult = make(chan prefetchResult, config.workers)
+
+	p.waitGroup.Add(1)
+	go p.read(stream, jobs)
+
+	for worker := 0; worker < config.workers; worker++ {
+		p.waitGroup.Add(1)
+		go p.work(jobs, config.preprocess)
+	}
+
+	go func() {
+		p.waitGroup.Wait()
+		close(p.results)
+	}()
+
+	return p
+}
+
+// read parses and batches samples. Only this goroutine touches the stream and its dataset.
+func (p *prefetcher) read(stream *batchStream, jobs chan<- prefetchResult) {
+	defer p.waitGroup.Done()
+	defer close(jobs)
+
+	for index := 0; ; index++ {
+		select {
+		case p.tokens <- struct{}{}:
+		case <-p.ctx.Done():
+			return
+		}
+
+		inputs, targets, err := stream.nextBatch()
+		if err == io.EOF {
+			<-p.tokens
+			return
+		}
+
+		if err != nil {
+			p.results <- prefetchResult{index: index, err: err}
+			return
+		}
+
+		select {
+		case jobs <- prefetchResult{index: index, inputs: inputs, targets: targets}:
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+
+func (p *prefetcher) work(jobs <-chan prefetchResult, preprocess batchPreprocessor) {
+	defer p.waitGroup.Done()
+
+	for job := range jobs {
+		if preprocess != nil {
+			job.inputs, job.targets, job.err = preprocess(job.inputs, job.targets)
+		}
+
+		// results holds a slot for every token, so this never blocks.
+		p.results <- job
+	}
+}
+
+// nextBatch returns the next batch in stream order, io.EOF after the last one, or the error of the context once
+// it is cancelled.
+func (p *prefetcher) nextBatch() (matrix, []int, error) {
+	for {
+		if result, ok := p.pending[p.nextIndex]; ok {
+			delete(p.pending, p.nextIndex)
+			p.nextIndex++
+			<-p.tokens
+
+			if result.err != nil {
+				p.cancel()
+				return nil, nil, fmt.Errorf("batch %d: %w", result.index+1, result.err)
+			}
+
+			return result.inputs, result.targets, nil
+		}
+
+		select {
+		case result, ok := <-p.results:
+			if !ok {
+				if err := p.ctx.Err(); err != nil {
+					return nil, nil, err
+				}
+
+				return nil, nil, io.EOF
+			}
+
+			p.pending[result.index] = result
+		case <-p.ctx.Done():
+			return nil, nil, p.ctx.Err()
+		}
+	}
+}
+
+// close stops the background goroutines and waits for them to exit.
+func (p *prefetcher) close() {
+	p.cancel()
+	for range p.results {
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingReadsDataset counts how many samples have been read from it.
+type countingReadsDataset struct {
+	*sliceDataset
+	reads int64
+}
+
+func (d *countingReadsDataset) next() (sample, error) {
+	atomic.AddInt64(&d.reads, 1)
+	return d.sliceDataset.next()
+}
+
+// failingDataset returns err instead of io.EOF after its samples.
+type failingDataset struct {
+	*sliceDataset
+	err error
+}
+
+func (d *failingDataset) next() (sample, error) {
+	next, err := d.sliceDataset.next()
+	if err == io.EOF {
+		return sample{}, d.err
+	}
+
+	return next, err
+}
+
+func TestPrefetcherKeepsStreamOrder(t *testing.T) {
+	var expected [][]int = readAllBatches(t, newBatchStream(countingDataset(50), 4, 10, rand.New(newSplitMix64Source(3))))
+
+	var delays *rand.Rand = rand.New(rand.NewSource(1))
+	var delayList []time.Duration
+	for index := 0; index < len(expected); index++ {
+		delayList = append(delayList, time.Duration(delays.Intn(3))*time.Millisecond)
+	}
+
+	var doubling *featureScaler = newFeatureScalerExplicit(scalerKindMaxAbs, vector{0}, vector{0.5})
+	var calls int64 = 0
+	var prefetch *prefetcher = newPrefetcher(context.Background(), newBatchStream(countingDataset(50), 4, 10, rand.New(newSplitMix64Source(3))), pipelineConfig{
+		workers:         4,
+		bufferedBatches: 2,
+		preprocess: func(inputs matrix, targets []int) (matrix, []int, error) {
+			time.Sleep(delayList[atomic.AddInt64(&calls, 1)-1])
+			return doubling.transform(inputs), targets, nil
+		},
+	})
+	defer prefetch.close()
+
+	for _, expectedTargets := range expected {
+		inputs, targets, err := prefetch.nextBatch()
+		require.NoError(t, err)
+		assert.Equal(t, expectedTargets, targets, "Batches should keep the stream order")
+		assert.Equal(t, float64(2*targets[0]), inputs[0][0], "Batches should be preprocessed")
+	}
+
+	_, _, err := prefetch.nextBatch()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestPrefetcherBoundsReadAhead(t *testing.T) {
+	var source *countingReadsDataset = &countingReadsDataset{sliceDataset: countingDataset(1000)}
+	var prefetch *prefetcher = newPrefetcher(context.Background(), newBatchStream(source, 5, 0, nil), pipelineConfig{workers: 2, bufferedBatches: 3})
+
+	time.Sleep(20 * time.Millisecond)
+	assert.LessOrEqual(t, atomic.LoadInt64(&source.reads), int64((2+3)*5), "At most workers + bufferedBatches batches should be read ahead")
+
+	_, _, err := prefetch.nextBatch()
+	require.NoError(t, err)
+	prefetch.close()
+	assert.Less(t, atomic.LoadInt64(&source.reads), int64(1000), "Close should stop reading")
+}
+
+func TestPrefetcherErrors(t *testing.T) {
+	var readErr error = errors.New("disk on fire")
+	var prefetch *prefetcher = newPrefetcher(context.Background(), newBatchStream(&failingDataset{sliceDataset: countingDataset(6), err: readErr}, 4, 0, nil), pipelineConfig{workers: 2})
+
+	_, _, err := prefetch.nextBatch()
+	require.NoError(t, err, "Batches before the failure should be returned")
+	_, _, err = prefetch.nextBatch()
+	assert.True(t, errors.Is(err, readErr), "Read errors should be returned in order")
+	prefetch.close()
+
+	var preprocessErr error = errors.New("bad batch")
+	prefetch = newPrefetcher(context.Background(), newBatchStream(countingDataset(20), 4, 0, nil), pipelineConfig{
+		workers: 3,
+		preprocess: func(inputs matrix, targets []int) (matrix, []int, error) {
+			if targets[0] == 8 {
+				return nil, nil, preprocessErr
+			}
+
+			return inputs, targets, nil
+		},
+	})
+
+	for index := 0; index < 2; index++ {
+		_, _, err = prefetch.nextBatch()
+		require.NoError(t, err)
+	}
+
+	_, _, err = prefetch.nextBatch()
+	assert.EqualError(t, err, "batch 3: bad batch")
+	prefetch.close()
+
+	assert.Panics(t, func() {
+		newPrefetcher(context.Background(), newBatchStream(countingDataset(1), 1, 0, nil), pipelineConfig{})
+	}, "Should panic without workers")
+	assert.Panics(t, func() {
+		newPrefetcher(context.Background(), newBatchStream(countingDataset(1), 1, 0, nil), pipelineConfig{workers: 1, bufferedBatches: -1})
+	}, "Should panic with negative buffered batches")
+}
+
+func TestPrefetcherCancellation(t *testing.T) {
+	var ctx, cancel = context.WithCancel(context.Background())
+	var prefetch *prefetcher = newPrefetcher(ctx, newBatchStream(countingDataset(1000), 1, 0, nil), pipelineConfig{workers: 2, bufferedBatches: 1})
+	defer prefetch.close()
+
+	_, _, err := prefetch.nextBatch()
+	require.NoError(t, err)
+
+	cancel()
+	for err == nil {
+		_, _, err = prefetch.nextBatch()
+	}
+
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestTrainPipelineMatchesTrainStream(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var config trainingConfig = trainingConfig{epochs: 4, batchSize: 2, learningRateStart: 0.5, shuffle: true, shuffleBufferSize: 4, seed: 9}
+
+	var streamed network = newTrainingTestNetwork(7)
+	var streamTrainer trainer = newTrainer(&streamed, config)
+	require.NoError(t, streamTrainer.trainStream(newSliceDataset(inputs, targets)))
+
+	var pipelined network = newTrainingTestNetwork(7)
+	var pipelineTrainer trainer = newTrainer(&pipelined, config)
+	require.NoError(t, pipelineTrainer.trainPipeline(context.Background(), newSliceDataset(inputs, targets), pipelineConfig{workers: 3, bufferedBatches: 2}))
+
+	assert.Equal(t, snapshotParameters(&streamed), snapshotParameters(&pipelined), "Prefetching should not change what is trained")
+}
+
+func TestTrainPipelineCancellation(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var ctx, cancel = context.WithCancel(context.Background())
+	var net network = newTrainingTestNetwork(7)
+	var pipelineTrainer trainer = newTrainer(&net, trainingConfig{epochs: 1000, batchSize: 2, learningRateStart: 0.1}, &cancellingCallback{cancel: cancel, afterEpoch: 3})
+
+	err := pipelineTrainer.trainPipeline(ctx, newSliceDataset(inputs, targets), pipelineConfig{workers: 2})
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, 3, pipelineTrainer.completedEpochs)
+}
+
+// cancellingCallback cancels a context at the end of an epoch.
+type cancellingCallback struct {
+	baseCallback
+	cancel     context.CancelFunc
+	afterEpoch int
+}
+
+func (c *cancellingCallback) onEpochEnd(state *trainingState) error {
+	if state.epoch == c.afterEpoch {
+		c.cancel()
+	}
+
+	return nil
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	scalerKindStandard string = "standard"
+	scalerKindMinMax   string = "minMax"
+	scalerKindRobust   string = "robust"
+	scalerKindMaxAbs   string = "maxAbs"
+)
+
+// featureScaler transforms every column as (value - center) / scale with statistics fitted on training data:
+//   - standard: mean and standard deviation
+//   - minMax: minimum and range, mapping the training data into [0, 1]
+//   - robust: median and interquartile range
+//   - maxAbs: 0 and the largest absolute value, mapping the training data into [-1, 1]
+//
+// Columns with a scale of 0 are only centered. A fitted scaler is also a component, so placing it first in a
+// network applies the same transformation to training and prediction inputs and saves it with the network.
+type featureScaler struct {
+	kind             string
+	centers          vector
+	scales           vector
+	inputDerivatives matrix
+}
+
+func newFeatureScaler(kind string) *featureScaler {
+	s, err := tryNewFeatureScaler(kind)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return s
+}
+
+// tryNewFeatureScaler is newFeatureScaler returning an error for an unknown kind instead of panicking.
+func tryNewFeatureScaler(kind string) (*featureScaler, error) {
+	switch kind {
+	case scalerKindStandard, scalerKindMinMax, scalerKindRobust, scalerKindMaxAbs:
+		return &featureScaler{kind: kind}, nil
+	}
+
+	return nil, fmt.Errorf("Unknown scaler kind %q", kind)
+}
+
+// newFeatureScalerExplicit creates an already fitted scaler.
+func newFeatureScalerExplicit(kind string, centers, scales vector) *featureScaler {
+	s, err := tryNewFeatureScalerExplicit(kind, centers, scales)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return s
+}
+
+// tryNewFeatureScalerExplicit is newFeatureScalerExplicit returning a shapeMismatchError or an error for an
+// unknown kind, a non-finite center or a zero or non-finite scale instead of panicking.
+func tryNewFeatureScalerExplicit(kind string, centers, scales vector) (*featureScaler, error) {
+	if len(centers) == 0 || len(centers) != len(scales) {
+		return nil, newShapeMismatchError(len(centers), len(scales), "Scaler center count %d does not match scale count %d", len(centers), len(scales))
+	}
+
+	for index := range centers {
+		if math.IsNaN(centers[index]) || math.IsInf(centers[index], 0) {
+			return nil, fmt.Errorf("Scaler center %v of column %d is not finite", centers[index], index)
+		}
+
+		if scales[index] == 0 || math.IsNaN(scales[index]) || math.IsInf(scales[index], 0) {
+			return nil, fmt.Errorf("Scaler scale %v of column %d is not a finite non-zero value", scales[index], index)
+		}
+	}
+
+	s, err := tryNewFeatureScaler(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	s.centers = centers
+	s.scales = scales
+	return s, nil
+}
+
+func column(data matrix, columnIndex int) vector {
+	var values vector = make(vector, len(data))
+	for rowIndex, row := range data {
+		values[rowIndex] = row[columnIndex]
+	}
+
+	return values
+}
+
+// quantile interpolates linearly between the closest ranks of sorted.
+func quantile(sorted vector, q float64) float64 {
+	var position float64 = q * float64(len(sorted)-1)
+	var lower int = int(math.Floor(position))
+	var upper int = int(math.Ceil(position))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(position-float64(lower))
+}
+
+func (s *featureScaler) fit(data matrix) {
+	if err := s.tryFit(data); err != nil {
+		panic(err.Error())
+	}
+}
+
+// tryFit is fit returning an invalidDimensionError or shapeMismatchError instead of panicking.
+func (s *featureScaler) tryFit(data matrix) error {
+	if len(data) == 0 {
+		return newInvalidDimensionError("row count", 0, "Can not fit scaler to empty data")
+	}
+
+	if len(data[0]) == 0 {
+		return newInvalidDimensionError("column count", 0, "Can not fit scaler to empty data")
+	}
+
+	var columnCount int = len(data[0])
+	for _, row := range data {
+		if len(row) != columnCount {
+			return newShapeMismatchError(columnCount, len(row), "Can not fit scaler to rows of differing length %d and %d", columnCount, len(row))
+		}
+	}
+
+	s.centers = make(vector, columnCount)
+	s.scales = make(vector, columnCount)
+
+	for columnIndex := 0; columnIndex < columnCount; columnIndex++ {
+		var values vector = column(data, columnIndex)
+
+		switch s.kind {
+		case scalerKindStandard:
+			var mean float64 = vectorSum(values) / float64(len(values))
+			var squaredDeviations float64 = 0
+			for _, value := range values {
+				squaredDeviations += (value - mean) * (value - mean)
+			}
+
+			s.centers[columnIndex] = mean
+			s.scales[columnIndex] = math.Sqrt(squaredDeviations / float64(len(values)))
+		case scalerKindMinMax:
+			var maxValue float64 = vectorMax(values)
+			var minValue float64 = vectorMin(values)
+
+			s.centers[columnIndex] = minValue
+			s.scales[columnIndex] = maxValue - minValue
+		case scalerKindRobust:
+			sort.Float64s(values)
+
+			s.centers[columnIndex] = quantile(values, 0.5)
+			s.scales[columnIndex] = quantile(values, 0.75) - quantile(values, 0.25)
+		case scalerKindMaxAbs:
+			for index, value := range values {
+				values[index] = math.Abs(value)
+			}
+
+			s.scales[columnIndex] = vectorMax(values)
+		}
+
+		if s.scales[columnIndex] == 0 {
+			s.scales[columnIndex] = 1
+		}
+	}
+
+	return nil
+}
+
+func (s featureScaler) transform(data matrix) matrix {
+	output, err := s.tryTransform(data)
+	if err != nil {
+		panic(err.Error())
+	}
+
+	return output
+}
+
+// tryTransform is transform returning a shapeMismatchError, or an error for an unfitted scaler, instead of
+// panicking.
+func (s featureScaler) tryTransform(data matrix) (matrix, error) {
+	if s.centers == nil {
+		return nil, errors.New("Scaler has not been fitted. Can not transform")
+	}
+
+	var output matrix = make(matrix, len(data))
+
+	for rowIndex, row := range data {
+		if len(row) != len(s.centers) {
+			return nil, newShapeMismatchError(len(s.centers), len(row), "Scaler column count %d does not match len of provided row %d", len(s.centers), len(row))
+		}
+
+		output[rowIndex] = make(vector, len(row))
+		for columnIndex, value := range row {
+			output[rowIndex][columnIndex] = (value - s.centers[columnIndex]) / s.scales[columnIndex]
+		}
+	}
+
+	return output, nil
+}
+
+func (s *featureScaler) fitTransform(data matrix) matrix {
+	s.fit(data)
+	return s.transform(data)
+}
+
+func (s *featureScaler) forward(input matrix) matrix {
+	return s.transform(input)
+}
+
+func (s featureScaler) predict(input matrix) matrix {
+	return s.transform(input)
+}
+
+func (s featureScaler) getInputDerivatives() matrix {
+	return s.inputDerivatives
+}
+
+func (s *featureScaler) backward(forwardInputDerivatives matrix) {
+	if err := s.tryBackward(forwardInputDerivatives); err != nil {
+		panic(err.Error())
+	}
+}
+
+// tryBackward is backward returning a shapeMismatchError instead of panicking.
+func (s *featureScaler) tryBackward(forwardInputDerivatives matrix) error {
+	var inputDerivatives matrix = make(matrix, len(forwardInputDerivatives))
+
+	for rowIndex, row := range forwardInputDerivatives {
+		if len(row) != len(s.scales) {
+			return newShapeMismatchError(len(s.scales), len(row), "The passed forward input derivative contains a row whose length %d does not match the scaler column count %d", len(row), len(s.scales))
+		}
+
+		inputDerivatives[rowIndex] = make(vector, len(row))
+		for columnIndex, value := range row {
+			inputDerivatives[rowIndex][columnIndex] = value / s.scales[columnIndex]
+		}
+	}
+
+	s.inputDerivatives = inputDerivatives
+	return nil
+}
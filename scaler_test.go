@@ -0,0 +1,98 @@
+package main
+
+import (
+	"math"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scalerTestData has a skewed column, a constant column and a column with negative values.
+func scalerTestData() matrix {
+	return matrix{
+		{1, 5, -4},
+		{2, 5, 0},
+		{3, 5, 2},
+		{4, 5, -1},
+		{10, 5, 3},
+	}
+}
+
+func TestFeatureScalerFit(t *testing.T) {
+	var cases map[string][2]vector = map[string][2]vector{
+		scalerKindStandard: {{4, 5, 0}, {math.Sqrt(10), 1, math.Sqrt(6)}},
+		scalerKindMinMax:   {{1, 5, -4}, {9, 1, 7}},
+		scalerKindRobust:   {{3, 5, 0}, {2, 1, 3}},
+		scalerKindMaxAbs:   {{0, 0, 0}, {10, 5, 4}},
+	}
+
+	for kind, expected := range cases {
+		var s *featureScaler = newFeatureScaler(kind)
+		s.fit(scalerTestData())
+
+		assert.InDeltaSlice(t, expected[0], s.centers, 1e-12, kind)
+		assert.InDeltaSlice(t, expected[1], s.scales, 1e-12, kind)
+	}
+}
+
+func TestFeatureScalerTransform(t *testing.T) {
+	var minMax *featureScaler = newFeatureScaler(scalerKindMinMax)
+	var transformed matrix = minMax.fitTransform(scalerTestData())
+
+	assert.Equal(t, vector{0, 0, 0}, transformed[0])
+	assert.Equal(t, vector{1, 0, 1}, transformed[4])
+	assert.Equal(t, matrix{{5.5, 5, -11}}, minMax.transform(matrix{{50.5, 10, -81}}), "Transform should use the fitted statistics for new data")
+
+	var standard *featureScaler = newFeatureScaler(scalerKindStandard)
+	transformed = standard.fitTransform(scalerTestData())
+	for columnIndex := 0; columnIndex < 3; columnIndex++ {
+		var values vector = column(transformed, columnIndex)
+		assert.InDelta(t, 0, vectorSum(values)/float64(len(values)), 1e-12, "Standardized columns should have mean 0")
+	}
+}
+
+func TestFeatureScalerPanics(t *testing.T) {
+	assert.Panics(t, func() { newFeatureScaler("log") }, "Should panic with unknown kind")
+	assert.Panics(t, func() { newFeatureScaler(scalerKindStandard).transform(matrix{{1}}) }, "Should panic when not fitted")
+	assert.Panics(t, func() { newFeatureScaler(scalerKindStandard).fit(matrix{}) }, "Should panic with empty data")
+	assert.Panics(t, func() { newFeatureScaler(scalerKindStandard).fit(matrix{{1, 2}, {1}}) }, "Should panic with ragged data")
+
+	var s *featureScaler = newFeatureScaler(scalerKindStandard)
+	s.fit(scalerTestData())
+	assert.Panics(t, func() { s.transform(matrix{{1, 2}}) }, "Should panic when the column count does not match")
+}
+
+func TestFeatureScalerGradientCheck(t *testing.T) {
+	var s *featureScaler = newFeatureScaler(scalerKindRobust)
+	s.fit(scalerTestData())
+
+	var report gradientCheckReport = checkComponentGradients(s, matrix{{1, 2, 3}, {-1, 0.5, 4}}, defaultGradientCheckEpsilon)
+	assert.Empty(t, report.failures(1e-6), report.String())
+}
+
+func TestFeatureScalerSavedWithNetwork(t *testing.T) {
+	rand.Seed(3)
+	var s *featureScaler = newFeatureScaler(scalerKindStandard)
+	s.fit(scalerTestData())
+	var l layer = newLayer(2, 3)
+	var original network = newNetwork(&crossentropy{}, s, &l, &softmax{})
+	var path string = filepath.Join(t.TempDir(), "model.json")
+
+	require.NoError(t, saveNetwork(&original, path))
+
+	loaded, err := loadNetwork(path)
+	require.NoError(t, err)
+	require.IsType(t, &featureScaler{}, loaded.components[0])
+	assert.Equal(t, s.centers, loaded.components[0].(*featureScaler).centers)
+	assert.Equal(t, original.predict(matrix{{7, 5, 1}}), loaded.predict(matrix{{7, 5, 1}}), "Loaded network should scale inputs the same way")
+
+	inputCount, err := networkInputCount(&loaded)
+	require.NoError(t, err)
+	assert.Equal(t, 3, inputCount)
+
+	var unfitted network = newNetwork(&crossentropy{}, newFeatureScaler(scalerKindMaxAbs), &l)
+	assert.Error(t, saveNetwork(&unfitted, path), "An unfitted scaler can not be saved")
+}
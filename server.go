@@ -0,0 +1,339 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// maxRequestBytes bounds the size of a prediction request body.
+const maxRequestBytes int64 = 10 << 20
+
+// predictionServer serves predictions of a trained network over HTTP. It only calls network.predict, so requests
+// are handled concurrently without locking.
+type predictionServer struct {
+	net                 *network
+	inputCount          int
+	classCount          int
+	labels              []string
+	outputsAreLogScaled bool
+}
+
+type predictRequest struct {
+	Input vector `json:"input"`
+}
+
+type batchPredictRequest struct {
+	Inputs matrix `json:"inputs"`
+}
+
+type prediction struct {
+	Probabilities vector `json:"probabilities"`
+	Class         int    `json:"class"`
+	Label         string `json:"label"`
+}
+
+type batchPredictResponse struct {
+	Predictions []prediction `json:"predictions"`
+}
+
+type modelInfo struct {
+	Status     string   `json:"status"`
+	InputCount int      `json:"inputCount"`
+	ClassCount int      `json:"classCount"`
+	Labels     []string `json:"labels"`
+	Components []string `json:"components"`
+	Loss       string   `json:"loss"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// networkInputCount returns the input count of the first component, which has to be a layer, dense layer or
+// fitted scaler.
+func networkInputCount(n *network) (int, error) {
+	switch first := n.components[0].(type) {
+	case *layer:
+		return first.inputCount, nil
+	case *denseLayer:
+		return first.inputCount, nil
+	case *featureScaler:
+		if first.centers != nil {
+			return len(first.centers), nil
+		}
+	}
+
+	return 0, fmt.Errorf("can not determine the input count of %s, the first component must be a layer or fitted scaler", n.describeComponent(0))
+}
+
+// newPredictionServer describes net for serving. Classes without a label in net.labels are labelled by their index.
+func newPredictionServer(net *network) (*predictionServer, error) {
+	inputCount, err := networkInputCount(net)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = net.checkComponentWidths(); err != nil {
+		return nil, err
+	}
+
+	var classCount int = len(net.predict(matrix{make(vector, inputCount)})[0])
+	if len(net.labels) != 0 && len(net.labels) != classCount {
+		return nil, fmt.Errorf("network has %d labels but %d outputs", len(net.labels), classCount)
+	}
+
+	var labels []string = make([]string, classCount)
+	for index := range labels {
+		labels[index] = strconv.Itoa(index)
+		if len(net.labels) != 0 {
+			labels[index] = net.labels[index]
+		}
+	}
+
+	_, outputsAreLogScaled := net.components[len(net.components)-1].(*logSoftmax)
+
+	return &predictionServer{
+		net:                 net,
+		inputCount:          inputCount,
+		classCount:          classCount,
+		labels:              labels,
+		outputsAreLogScaled: outputsAreLogScaled,
+	}, nil
+}
+
+func (s *predictionServer) handler() http.Handler {
+	var mux *http.ServeMux = http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/predict", s.handlePredict)
+	mux.HandleFunc("/predict/batch", s.handleBatchPredict)
+	return mux
+}
+
+// writeJSON encodes value before writing the header, so a value that can not be encoded, such as one holding NaN,
+// is answered with a 500 instead of a 200 with a truncated body.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		log.Printf("Can not encode response: %v", err)
+		status = http.StatusInternalServerError
+		body, _ = json.Marshal(errorResponse{Error: "can not encode response"})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(body, '\n'))
+}
+
+func writeError(w http.ResponseWriter, status int, format string, args ...interface{}) {
+	writeJSON(w, status, errorResponse{Error: fmt.Sprintf(format, args...)})
+}
+
+func (s *predictionServer) info() modelInfo {
+	var components []string = make([]string, len(s.net.components))
+	for index, c := range s.net.components {
+		components[index] = fmt.Sprintf("%T", c)
+	}
+
+	return modelInfo{
+		Status:     "ok",
+		InputCount: s.inputCount,
+		ClassCount: s.classCount,
+		Labels:     s.labels,
+		Components: components,
+		Loss:       fmt.Sprintf("%T", s.net.loss),
+	}
+}
+
+func (s *predictionServer) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed, use GET", r.Method)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.info())
+}
+
+// decodeRequest reads a JSON body into value and writes an error response if that fails.
+func decodeRequest(w http.ResponseWriter, r *http.Request, value interface{}) bool {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "method %s not allowed, use POST", r.Method)
+		return false
+	}
+
+	var decoder *json.Decoder = json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(value); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: %v", err)
+		return false
+	}
+
+	return true
+}
+
+// validateInputs checks that every input has one finite value per input of the first layer.
+func (s *predictionServer) validateInputs(inputs matrix) error {
+	if len(inputs) == 0 {
+		return newInvalidDimensionError("batch size", 0, "request contains no inputs")
+	}
+
+	for index, input := range inputs {
+		if len(input) != s.inputCount {
+			return newShapeMismatchError(s.inputCount, len(input), "input %d has %d values but the model expects %d", index, len(input), s.inputCount)
+		}
+
+		for valueIndex, value := range input {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return fmt.Errorf("input %d has the non-finite value %v at index %d", index, value, valueIndex)
+			}
+		}
+	}
+
+	return nil
+}
+
+// predictions fails if an input drives the network to a non-finite output, which happens for valid but huge
+// inputs that overflow the layers.
+func (s *predictionServer) predictions(inputs matrix) ([]prediction, error) {
+	var outputs matrix = s.net.predict(inputs)
+	var predictions []prediction = make([]prediction, len(outputs))
+
+	for index, output := range outputs {
+		if s.outputsAreLogScaled {
+			for valueIndex, value := range output {
+				output[valueIndex] = math.Exp(value)
+			}
+		}
+
+		for _, value := range output {
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				return nil, fmt.Errorf("input %d drives the model to the non-finite output %v", index, value)
+			}
+		}
+
+		var class int = argmax(output)
+		predictions[index] = prediction{Probabilities: output, Class: class, Label: s.labels[class]}
+	}
+
+	return predictions, nil
+}
+
+func (s *predictionServer) handlePredict(w http.ResponseWriter, r *http.Request) {
+	var request predictRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	var inputs matrix = matrix{request.Input}
+	if err := s.validateInputs(inputs); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	predictions, err := s.predictions(inputs)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "%v", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, predictions[0])
+}
+
+func (s *predictionServer) handleBatchPredict(w http.ResponseWriter, r *http.Request) {
+	var request batchPredictRequest
+	if !decodeRequest(w, r, &request) {
+		return
+	}
+
+	if err := s.validateInputs(request.Inputs); err != nil {
+		writeError(w, http.StatusBadRequest, "%v", err)
+		return
+	}
+
+	predictions, err := s.predictions(request.Inputs)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, "%v", err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, batchPredictResponse{Predictions: predictions})
+}
+
+// runServe implements `lnet serve -model model.json [-addr :8080] [-grpc-addr :9090]`. With -grpc-addr the
+// Inference gRPC service is served next to the HTTP endpoints, and both servers are stopped as soon as either
+// fails.
+func runServe(args []string) error {
+	var flags *flag.FlagSet = flag.NewFlagSet("serve", flag.ContinueOnError)
+	var modelPath *string = flags.String("model", "", "path of the saved network to serve")
+	var address *string = flags.String("addr", ":8080", "address to listen on")
+	var grpcAddress *string = flags.String("grpc-addr", "", "address to serve gRPC on, disabled when empty")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *modelPath == "" {
+		return fmt.Errorf("serve requires -model")
+	}
+
+	loaded, err := loadNetwork(*modelPath)
+	if err != nil {
+		return err
+	}
+
+	server, err := newPredictionServer(&loaded)
+	if err != nil {
+		return err
+	}
+
+	var httpServer *http.Server = &http.Server{
+		Addr:              *address,
+		Handler:           server.handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	var errs chan error = make(chan error, 2)
+	var running int = 1
+	var grpcServer *grpc.Server
+
+	if *grpcAddress != "" {
+		listener, err := net.Listen("tcp", *grpcAddress)
+		if err != nil {
+			return err
+		}
+
+		log.Printf("Serving gRPC on %s", *grpcAddress)
+		grpcServer = newGRPCServer(server)
+		running++
+		go func() { errs <- grpcServer.Serve(listener) }()
+	}
+
+	log.Printf("Serving %s with %d inputs and %d classes on %s", *modelPath, server.inputCount, server.classCount, *address)
+	go func() { errs <- httpServer.ListenAndServe() }()
+
+	err = <-errs
+
+	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	httpServer.Shutdown(ctx)
+	if grpcServer != nil {
+		grpcServer.GracefulStop()
+	}
+
+	// Wait for the other server to return so its address is released
+	for ; running > 1; running-- {
+		<-errs
+	}
+
+	return err
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPredictionServer(t *testing.T) (*httptest.Server, *network) {
+	var l layer = newLayerExplicit(matrix{{1, 0}, {0, 1}, {-1, -1}}, vector{0, 0, 0})
+	var net network = newNetwork(&crossentropy{}, &l, &softmax{})
+	net.labels = []string{"first", "second", "neither"}
+
+	server, err := newPredictionServer(&net)
+	require.NoError(t, err)
+
+	var httpServer *httptest.Server = httptest.NewServer(server.handler())
+	t.Cleanup(httpServer.Close)
+	return httpServer, &net
+}
+
+func postJSON(t *testing.T, url string, body string, response interface{}) int {
+	result, err := http.Post(url, "application/json", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	defer result.Body.Close()
+
+	require.Equal(t, "application/json", result.Header.Get("Content-Type"))
+	require.NoError(t, json.NewDecoder(result.Body).Decode(response))
+	return result.StatusCode
+}
+
+func TestServerHealth(t *testing.T) {
+	httpServer, _ := newTestPredictionServer(t)
+
+	result, err := http.Get(httpServer.URL + "/health")
+	require.NoError(t, err)
+	defer result.Body.Close()
+
+	var info modelInfo
+	require.Equal(t, http.StatusOK, result.StatusCode)
+	require.NoError(t, json.NewDecoder(result.Body).Decode(&info))
+	assert.Equal(t, modelInfo{
+		Status:     "ok",
+		InputCount: 2,
+		ClassCount: 3,
+		Labels:     []string{"first", "second", "neither"},
+		Components: []string{"*main.layer", "*main.softmax"},
+		Loss:       "*main.crossentropy",
+	}, info)
+}
+
+func TestServerPredict(t *testing.T) {
+	httpServer, net := newTestPredictionServer(t)
+
+	var response prediction
+	require.Equal(t, http.StatusOK, postJSON(t, httpServer.URL+"/predict", `{"input": [0.2, 3]}`, &response))
+	assert.Equal(t, net.predict(matrix{{0.2, 3}})[0], response.Probabilities)
+	assert.Equal(t, 1, response.Class)
+	assert.Equal(t, "second", response.Label)
+}
+
+func TestServerBatchPredict(t *testing.T) {
+	httpServer, _ := newTestPredictionServer(t)
+
+	var response batchPredictResponse
+	require.Equal(t, http.StatusOK, postJSON(t, httpServer.URL+"/predict/batch", `{"inputs": [[3, 0], [0, 3], [-3, -3]]}`, &response))
+	require.Len(t, response.Predictions, 3)
+
+	for index, label := range []string{"first", "second", "neither"} {
+		assert.Equal(t, index, response.Predictions[index].Class)
+		assert.Equal(t, label, response.Predictions[index].Label)
+	}
+}
+
+func TestServerRejectsInvalidRequests(t *testing.T) {
+	httpServer, _ := newTestPredictionServer(t)
+
+	var requests map[string][2]string = map[string][2]string{
+		"malformed json":       {"/predict", `{"input": [1, 2`},
+		"unknown field":        {"/predict", `{"inputs": [1, 2]}`},
+		"too few values":       {"/predict", `{"input": [1]}`},
+		"too many values":      {"/predict", `{"input": [1, 2, 3]}`},
+		"empty batch":          {"/predict/batch", `{"inputs": []}`},
+		"mismatched batch row": {"/predict/batch", `{"inputs": [[1, 2], [1]]}`},
+	}
+
+	for name, request := range requests {
+		var response errorResponse
+		assert.Equal(t, http.StatusBadRequest, postJSON(t, httpServer.URL+request[0], request[1], &response), name)
+		assert.NotEmpty(t, response.Error, name)
+	}
+
+	var response errorResponse
+	assert.Equal(t, http.StatusBadRequest, postJSON(t, httpServer.URL+"/predict", `{"input": [1]}`, &response))
+	assert.Equal(t, "input 0 has 1 values but the model expects 2", response.Error)
+
+	result, err := http.Get(httpServer.URL + "/predict")
+	require.NoError(t, err)
+	result.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, result.StatusCode)
+}
+
+func TestServerRejectsNonFiniteOutputs(t *testing.T) {
+	// Huge inputs overflow the first neuron to Inf - Inf, so the outputs are NaN
+	var l layer = newLayerExplicit(matrix{{2, -2}, {1, 0}}, vector{0, 0})
+	var net network = newNetwork(&crossentropy{}, &l, &softmax{})
+	server, err := newPredictionServer(&net)
+	require.NoError(t, err)
+
+	var httpServer *httptest.Server = httptest.NewServer(server.handler())
+	defer httpServer.Close()
+
+	var response errorResponse
+	assert.Equal(t, http.StatusUnprocessableEntity, postJSON(t, httpServer.URL+"/predict", `{"input": [1e308, 1e308]}`, &response))
+	assert.Contains(t, response.Error, "non-finite output")
+
+	assert.Equal(t, http.StatusUnprocessableEntity, postJSON(t, httpServer.URL+"/predict/batch", `{"inputs": [[1, 1], [1e308, 1e308]]}`, &response))
+	assert.Contains(t, response.Error, "input 1")
+
+	assert.Error(t, server.validateInputs(matrix{{math.NaN(), 1}}), "Non-finite inputs should be rejected")
+}
+
+func TestWriteJSONFailsBeforeWritingHeader(t *testing.T) {
+	var recorder *httptest.ResponseRecorder = httptest.NewRecorder()
+	writeJSON(recorder, http.StatusOK, prediction{Probabilities: vector{math.NaN()}})
+
+	var response errorResponse
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code, "A response that can not be encoded should not be a 200")
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	assert.NotEmpty(t, response.Error)
+}
+
+func TestServerConvertsLogProbabilities(t *testing.T) {
+	var l denseLayer = newDenseLayerExplicit(matrix{{1, 0}, {0, 1}}, vector{0, 0})
+	var net network = newNetwork(&negativeLogLikelihood{}, &l, &logSoftmax{})
+
+	server, err := newPredictionServer(&net)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"0", "1"}, server.labels, "Classes without labels should be labelled by index")
+
+	predictions, err := server.predictions(matrix{{1, 2}})
+	require.NoError(t, err)
+	assert.InDelta(t, 1, vectorSum(predictions[0].Probabilities), 1e-12, "Log probabilities should be converted to probabilities")
+	assert.InDelta(t, 1/(1+math.E), predictions[0].Probabilities[0], 1e-12)
+}
+
+func TestNewPredictionServerErrors(t *testing.T) {
+	var relu network = newNetwork(&crossentropy{}, &reluActivation{})
+	_, err := newPredictionServer(&relu)
+	assert.Error(t, err, "The first component has to be a layer")
+
+	var first denseLayer = newDenseLayerExplicit(matrix{{1, 2}}, vector{0})
+	var second denseLayer = newDenseLayerExplicit(matrix{{1, 2, 3}}, vector{0})
+	var mismatched network = newNetwork(&crossentropy{}, &first, &second, &softmax{})
+	_, err = newPredictionServer(&mismatched)
+	requireShapeMismatch(t, err, 3, 1)
+
+	var l layer = newLayer(2, 2)
+	var labelled network = newNetwork(&crossentropy{}, &l, &softmax{})
+	labelled.labels = []string{"only one"}
+	_, err = newPredictionServer(&labelled)
+	assert.Error(t, err, "The label count has to match the output count")
+}
+
+func TestServeLoadsSavedLabels(t *testing.T) {
+	var l layer = newLayer(3, 2)
+	var net network = newNetwork(&crossentropy{}, &l, &softmax{})
+	net.labels = []string{"a", "b", "c"}
+	var path string = filepath.Join(t.TempDir(), "model.json")
+	require.NoError(t, saveNetwork(&net, path))
+
+	loaded, err := loadNetwork(path)
+	require.NoError(t, err)
+	assert.Equal(t, net.labels, loaded.labels)
+
+	assert.Error(t, runServe([]string{}), "Serve requires a model")
+	assert.Error(t, runServe([]string{"-model", filepath.Join(t.TempDir(), "missing.json")}))
+}
+
+func TestServeStopsGRPCWhenHTTPFails(t *testing.T) {
+	var l layer = newLayer(3, 2)
+	var model network = newNetwork(&crossentropy{}, &l, &softmax{})
+	var path string = filepath.Join(t.TempDir(), "model.json")
+	require.NoError(t, saveNetwork(&model, path))
+
+	// The HTTP address is taken, so serving HTTP fails right away while gRPC has already started
+	taken, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer taken.Close()
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var grpcAddress string = free.Addr().String()
+	require.NoError(t, free.Close())
+
+	assert.Error(t, runServe([]string{"-model", path, "-addr", taken.Addr().String(), "-grpc-addr", grpcAddress}))
+
+	listener, err := net.Listen("tcp", grpcAddress)
+	require.NoError(t, err, "The gRPC server should have been stopped and released its address")
+	listener.Close()
+}
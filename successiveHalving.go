@@ -0,0 +1,149 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+)
+
+// halvingConfig configures successiveHalving and hyperband. Successive halving trains trials configurations for
+// minEpochs epochs, keeps the best 1/reduction of them, resumes those for reduction times as many epochs and
+// repeats until the budget reaches maxEpochs. The epochs of the configs returned by the trial builder are
+// replaced by these budgets.
+type halvingConfig struct {
+	searchConfig
+	minEpochs int
+	maxEpochs int
+	reduction int
+}
+
+func (c halvingConfig) check(data searchData) {
+	checkSearch(data, c.searchConfig)
+
+	if c.minEpochs <= 0 || c.maxEpochs < c.minEpochs {
+		panic(fmt.Sprintf("Can not schedule budgets from %d to %d epochs", c.minEpochs, c.maxEpochs))
+	}
+
+	if c.reduction < 2 {
+		panic(fmt.Sprintf("Can not halve with reduction factor %d. Reduction must be at least 2", c.reduction))
+	}
+}
+
+// rankByBudget ranks trials that trained longer first, since they survived more rungs, and trials with the same
+// budget by their validation metric. Failed trials rank last.
+func rankByBudget(metric string, results []trialResult) searchResults {
+	var ranked searchResults = searchResults{metric: metric, trials: append([]trialResult{}, results...)}
+	sort.SliceStable(ranked.trials, func(i, j int) bool {
+		var a, b trialResult = ranked.trials[i], ranked.trials[j]
+		if (a.err == nil) != (b.err == nil) {
+			return a.err == nil
+		}
+
+		if a.epochs != b.epochs {
+			return a.epochs > b.epochs
+		}
+
+		return ranked.better(a, b)
+	})
+
+	return ranked
+}
+
+// runSuccessiveHalving resumes the surviving trials with a growing budget starting at firstBudget and returns the
+// results of all trials, each with the metrics of the last rung it reached.
+func runSuccessiveHalving(trials []*searchTrial, data searchData, config halvingConfig, firstBudget int) []trialResult {
+	var byTrial map[int]*searchTrial = map[int]*searchTrial{}
+	for _, trial := range trials {
+		byTrial[trial.result.trial] = trial
+	}
+
+	var survivors []*searchTrial = trials
+	for budget := firstBudget; ; budget = minInt(budget*config.reduction, config.maxEpochs) {
+		trainTrials(survivors, data, config.workers, budget)
+		if budget == config.maxEpochs || len(survivors) == 0 {
+			break
+		}
+
+		var results []trialResult = make([]trialResult, len(survivors))
+		for index, trial := range survivors {
+			results[index] = trial.result
+		}
+
+		var ranked searchResults = newSearchResults(config.metric, results)
+		survivors = nil
+		for _, result := range ranked.trials[:maxInt(len(ranked.trials)/config.reduction, 1)] {
+			if result.err == nil {
+				survivors = append(survivors, byTrial[result.trial])
+			}
+		}
+	}
+
+	var results []trialResult = make([]trialResult, len(trials))
+	for index, trial := range trials {
+		results[index] = trial.result
+	}
+
+	return results
+}
+
+// successiveHalving samples config.trials configurations from spaces and races them with successive halving.
+// Survivors continue training where they stopped rather than starting over, and the results are the same for
+// the same seed whatever the number of workers.
+func successiveHalving(spaces map[string]parameterSpace, build trialBuilder, data searchData, config halvingConfig) searchResults {
+	config.check(data)
+	if config.trials <= 0 {
+		panic(fmt.Sprintf("Can not run %d trials", config.trials))
+	}
+
+	var combinations []hyperparameters = randomSearchParameters(spaces, config.trials, rand.New(newSplitMix64Source(deriveSeed(config.seed, seedPurposeSampling))))
+	var trials []*searchTrial = newSearchTrials(combinations, build, config.seed)
+
+	return rankByBudget(config.metric, runSuccessiveHalving(trials, data, config, config.minEpochs))
+}
+
+// hyperbandBrackets returns the trial count and first budget of every Hyperband bracket, from the most
+// exploratory bracket, which starts the most trials with minEpochs, to plain training of a few trials with
+// maxEpochs.
+func hyperbandBrackets(config halvingConfig) [][2]int {
+	var rungs int = 0
+	for budget := config.minEpochs * config.reduction; budget <= config.maxEpochs; budget *= config.reduction {
+		rungs++
+	}
+
+	var brackets [][2]int
+	for s := rungs; s >= 0; s-- {
+		var scale int = 1
+		for index := 0; index < s; index++ {
+			scale *= config.reduction
+		}
+
+		var trials int = ((rungs+1)*scale + s) / (s + 1)
+		brackets = append(brackets, [2]int{trials, maxInt(config.maxEpochs/scale, 1)})
+	}
+
+	return brackets
+}
+
+// hyperband runs successive halving in several brackets that trade the number of configurations against the
+// epochs each starts with, hedging against early epochs being a poor predictor of final quality. config.trials
+// is ignored. Trials are numbered and seeded across brackets, and the results are ranked by budget and metric.
+func hyperband(spaces map[string]parameterSpace, build trialBuilder, data searchData, config halvingConfig) searchResults {
+	config.check(data)
+
+	var random *rand.Rand = rand.New(newSplitMix64Source(deriveSeed(config.seed, seedPurposeSampling)))
+	var results []trialResult
+	var trialCount int = 0
+
+	for _, bracket := range hyperbandBrackets(config) {
+		var combinations []hyperparameters = randomSearchParameters(spaces, bracket[0], random)
+		var trials []*searchTrial = newSearchTrials(combinations, build, config.seed+int64(trialCount))
+		for _, trial := range trials {
+			trial.result.trial += trialCount
+		}
+
+		results = append(results, runSuccessiveHalving(trials, data, config, bracket[1])...)
+		trialCount += len(trials)
+	}
+
+	return rankByBudget(config.metric, results)
+}
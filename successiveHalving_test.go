@@ -0,0 +1,129 @@
+package main
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var halvingTestSpaces map[string]parameterSpace = map[string]parameterSpace{
+	"hidden":       discreteSpace(2, 4, 8),
+	"epochs":       discreteSpace(1),
+	"learningRate": logUniformSpace(0.001, 0.5),
+}
+
+func halvingTestConfig(workers int) halvingConfig {
+	return halvingConfig{
+		searchConfig: searchConfig{trials: 9, workers: workers, seed: 2, metric: "loss"},
+		minEpochs:    2,
+		maxEpochs:    18,
+		reduction:    3,
+	}
+}
+
+func TestSuccessiveHalving(t *testing.T) {
+	var results searchResults = successiveHalving(halvingTestSpaces, buildSearchTestTrial, searchTestData(), halvingTestConfig(3))
+
+	require.Len(t, results.trials, 9)
+	var epochCounts map[int]int = map[int]int{}
+	for _, result := range results.trials {
+		require.NoError(t, result.err)
+		epochCounts[result.epochs]++
+	}
+
+	assert.Equal(t, map[int]int{2: 6, 6: 2, 18: 1}, epochCounts, "A third of the trials should survive every rung")
+	assert.Equal(t, 18, results.best().epochs, "The trial trained with the full budget should rank first")
+
+	for index := 1; index < len(results.trials); index++ {
+		var previous, current trialResult = results.trials[index-1], results.trials[index]
+		assert.True(t, previous.epochs > current.epochs || previous.metrics["val_loss"] <= current.metrics["val_loss"], "Trials should be ranked by budget, then validation loss")
+	}
+}
+
+func TestSuccessiveHalvingIsDeterministic(t *testing.T) {
+	var sequential searchResults = successiveHalving(halvingTestSpaces, buildSearchTestTrial, searchTestData(), halvingTestConfig(1))
+	var parallel searchResults = successiveHalving(halvingTestSpaces, buildSearchTestTrial, searchTestData(), halvingTestConfig(4))
+
+	assert.Equal(t, sequential, parallel, "The number of workers should not change the results")
+}
+
+func TestSuccessiveHalvingResumesTrials(t *testing.T) {
+	var data searchData = searchTestData()
+	var parameters []hyperparameters = []hyperparameters{{"hidden": 4, "epochs": 1, "learningRate": 0.1}}
+
+	var resumed *searchTrial = newSearchTrials(parameters, buildSearchTestTrial, 5)[0]
+	trainTrials([]*searchTrial{resumed}, data, 1, 3)
+	trainTrials([]*searchTrial{resumed}, data, 1, 9)
+
+	var uninterrupted *searchTrial = newSearchTrials(parameters, buildSearchTestTrial, 5)[0]
+	trainTrials([]*searchTrial{uninterrupted}, data, 1, 9)
+
+	assert.Equal(t, 9, resumed.result.epochs)
+	assert.Equal(t, snapshotParameters(&uninterrupted.net), snapshotParameters(&resumed.net), "Resuming a trial should continue its training rather than restart it")
+	assert.Equal(t, uninterrupted.result.metrics, resumed.result.metrics)
+}
+
+func TestHyperbandBrackets(t *testing.T) {
+	var config halvingConfig = halvingConfig{minEpochs: 1, maxEpochs: 81, reduction: 3}
+
+	assert.Equal(t, [][2]int{{81, 1}, {34, 3}, {15, 9}, {8, 27}, {5, 81}}, hyperbandBrackets(config), "Brackets should match the Hyperband paper")
+
+	config.minEpochs = 81
+	assert.Equal(t, [][2]int{{1, 81}}, hyperbandBrackets(config))
+}
+
+func TestHyperband(t *testing.T) {
+	var config halvingConfig = halvingTestConfig(4)
+	config.maxEpochs = 6
+
+	var results searchResults = hyperband(halvingTestSpaces, buildSearchTestTrial, searchTestData(), config)
+	var again searchResults = hyperband(halvingTestSpaces, buildSearchTestTrial, searchTestData(), config)
+
+	require.Len(t, results.trials, 3+2)
+	assert.Equal(t, results, again, "Hyperband should be deterministic for a seed")
+	assert.Equal(t, 6, results.best().epochs)
+
+	var trialNumbers map[int]bool = map[int]bool{}
+	for _, result := range results.trials {
+		trialNumbers[result.trial] = true
+		assert.Equal(t, config.seed+int64(result.trial-1), result.seed, "Trials should be numbered and seeded across brackets")
+	}
+	assert.Len(t, trialNumbers, 5)
+}
+
+func TestSuccessiveHalvingFailedTrials(t *testing.T) {
+	var config halvingConfig = halvingTestConfig(2)
+	config.trials = 3
+
+	var results searchResults = successiveHalving(map[string]parameterSpace{
+		"hidden":       discreteSpace(4),
+		"epochs":       discreteSpace(1),
+		"learningRate": discreteSpace(math.Inf(1)),
+	}, buildSearchTestTrial, searchTestData(), config)
+
+	for _, result := range results.trials {
+		assert.Error(t, result.err, "Every trial should fail the numeric health check")
+	}
+}
+
+func TestHalvingConfigPanics(t *testing.T) {
+	var invalid halvingConfig = halvingTestConfig(1)
+	invalid.reduction = 1
+	assert.Panics(t, func() { successiveHalving(halvingTestSpaces, buildSearchTestTrial, searchTestData(), invalid) }, "Should panic with reduction 1")
+
+	invalid = halvingTestConfig(1)
+	invalid.maxEpochs = 1
+	assert.Panics(t, func() { hyperband(halvingTestSpaces, buildSearchTestTrial, searchTestData(), invalid) }, "Should panic with max epochs below min epochs")
+
+	invalid = halvingTestConfig(1)
+	invalid.trials = 0
+	assert.Panics(t, func() { successiveHalving(halvingTestSpaces, buildSearchTestTrial, searchTestData(), invalid) }, "Should panic without trials")
+}
+
+func TestSearchCommandSchedulerErrors(t *testing.T) {
+	assert.Error(t, runSearchCommand([]string{"-scheduler", "bayesian"}), "Unknown schedulers should be rejected")
+	assert.Error(t, runSearchCommand([]string{"-scheduler", "halving", "-reduction", "1"}), "halving needs a reduction of at least 2")
+	assert.Error(t, runSearchCommand([]string{"-scheduler", "hyperband", "-min-epochs", "10", "-max-epochs", "5"}), "hyperband needs ordered budgets")
+}
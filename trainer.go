@@ -0,0 +1,476 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+)
+
+// trainingConfig describes a training run. With a checkpointPath the trainer writes a checkpoint every
+// checkpointRate epochs, and with resume it continues from that checkpoint if one exists.
+// clipValue and clipNorm configure gradientClipping and are disabled when 0. With checkNumericHealth every batch
+// checks activations, loss, derivatives, gradients and parameters and aborts training with a numericHealthError
+// on the first value that is NaN or infinite. shuffleBufferSize bounds the samples trainStream holds to shuffle
+// and defaults to defaultShuffleBufferSize.
+type trainingConfig struct {
+	epochs             int
+	batchSize          int
+	learningRateStart  float64
+	learningRateDecay  float64
+	momentum           float64
+	clipValue          float64
+	clipNorm           float64
+	checkNumericHealth bool
+	shuffle            bool
+	shuffleBufferSize  int
+	seed               int64
+	checkpointPath     string
+	checkpointRate     int
+	resume             bool
+}
+
+// trainingState is what callbacks receive. loss holds the batch loss inside batch hooks and the average loss
+// of the epoch inside epoch and train hooks. Callbacks may set stopTraining to end training after the current epoch.
+// The grad_norm metric is the global gradient norm before clipping; for an epoch it is the largest of its batches.
+// batchesPerEpoch is known before training on in-memory data and 0 for streamed datasets.
+type trainingState struct {
+	net             *network
+	epoch           int
+	batch           int
+	batchesPerEpoch int
+	loss            float64
+	metrics         map[string]float64
+	learningRate    float64
+	stopTraining    bool
+}
+
+// inverseTimeDecay lowers the learning rate each epoch as start / (1 + decay * epoch).
+type inverseTimeDecay struct {
+	learningRateStart float64
+	learningRateDecay float64
+}
+
+func (s inverseTimeDecay) learningRate(epoch int) float64 {
+	return s.learningRateStart * (1 / (1 + s.learningRateDecay*float64(epoch)))
+}
+
+// sgdOptimizer applies stochastic gradient descent, optionally with momentum. With momentum each trainable
+// keeps a velocity that accumulates past updates: velocity = momentum * velocity - learningRate * gradient.
+type sgdOptimizer struct {
+	learningRate float64
+	momentum     float64
+	velocities   []vector
+}
+
+func (o *sgdOptimizer) step(trainables []trainable) {
+	if o.momentum != 0 && o.velocities == nil {
+		o.velocities = make([]vector, len(trainables))
+		for index, t := range trainables {
+			o.velocities[index] = make(vector, t.parameterCount())
+		}
+	}
+
+	for index, t := range trainables {
+		var parameters vector = t.getParameters()
+
+		for parameterIndex, derivativeValue := range t.getParameterGradients() {
+			if o.momentum == 0 {
+				parameters[parameterIndex] = parameters[parameterIndex] + (-1 * derivativeValue * o.learningRate)
+				continue
+			}
+
+			var velocity vector = o.velocities[index]
+			velocity[parameterIndex] = o.momentum*velocity[parameterIndex] - derivativeValue*o.learningRate
+			parameters[parameterIndex] = parameters[parameterIndex] + velocity[parameterIndex]
+		}
+
+		t.setParameters(parameters)
+	}
+}
+
+// validationMetricPrefix marks metrics measured on the validation data rather than the training data.
+const validationMetricPrefix string = "val_"
+
+type trainer struct {
+	net               *network
+	config            trainingConfig
+	callbacks         []callback
+	scheduler         inverseTimeDecay
+	optimizer         sgdOptimizer
+	clipping          gradientClipping
+	randomSource      *splitMix64Source
+	random            *rand.Rand
+	completedEpochs   int
+	validationInputs  matrix
+	validationTargets []int
+}
+
+func newTrainer(net *network, config trainingConfig, callbacks ...callback) trainer {
+	if config.epochs <= 0 {
+		panic(fmt.Sprintf("Can not train for %d epochs", config.epochs))
+	}
+
+	if config.batchSize < 0 {
+		panic(fmt.Sprintf("Can not train with batch size %d", config.batchSize))
+	}
+
+	if config.momentum < 0 || config.momentum >= 1 {
+		panic(fmt.Sprintf("Can not train with momentum %f. Momentum must be in [0, 1)", config.momentum))
+	}
+
+	if config.checkpointPath != "" && config.checkpointRate <= 0 {
+		panic(fmt.Sprintf("Can not checkpoint with checkpoint rate %d", config.checkpointRate))
+	}
+
+	var randomSource *splitMix64Source = newSplitMix64Source(config.seed)
+
+	return trainer{
+		net:          net,
+		config:       config,
+		callbacks:    callbacks,
+		scheduler:    inverseTimeDecay{learningRateStart: config.learningRateStart, learningRateDecay: config.learningRateDecay},
+		optimizer:    sgdOptimizer{learningRate: config.learningRateStart, momentum: config.momentum},
+		clipping:     newGradientClipping(config.clipValue, config.clipNorm),
+		randomSource: randomSource,
+		random:       rand.New(randomSource),
+	}
+}
+
+// setValidationData makes the trainer report val_loss and val_accuracy for inputs and targets after every epoch.
+func (t *trainer) setValidationData(inputs matrix, targets []int) {
+	if len(inputs) == 0 {
+		panic("Can not validate on empty input batch")
+	}
+
+	if len(inputs) != len(targets) {
+		panic(fmt.Sprintf("Validation targets length %d does not match input batch size %d", len(targets), len(inputs)))
+	}
+
+	t.validationInputs = inputs
+	t.validationTargets = targets
+}
+
+// batchRanges splits sampleCount samples into consecutive batches. A batch size of 0 trains on the full batch.
+func (t *trainer) batchRanges(sampleCount int) [][2]int {
+	var batchSize int = t.config.batchSize
+	if batchSize == 0 || batchSize > sampleCount {
+		batchSize = sampleCount
+	}
+
+	var ranges [][2]int
+	for start := 0; start < sampleCount; start += batchSize {
+		ranges = append(ranges, [2]int{start, minInt(start+batchSize, sampleCount)})
+	}
+
+	return ranges
+}
+
+func (t *trainer) sampleOrder(sampleCount int) []int {
+	var order []int = make([]int, sampleCount)
+	for index := range order {
+		order[index] = index
+	}
+
+	if t.config.shuffle {
+		t.random.Shuffle(sampleCount, func(i, j int) {
+			order[i], order[j] = order[j], order[i]
+		})
+	}
+
+	return order
+}
+
+// trainBatch runs one optimization step and reports the batch loss, accuracy and pre-clip gradient norm in state.
+func (t *trainer) trainBatch(inputs matrix, targets []int, state *trainingState) error {
+	var loss float64 = t.net.forward(inputs, targets)
+	var accuracy float64 = calculateAccuracy(t.net.lastOutput, targets)
+
+	if t.config.checkNumericHealth {
+		if err := checkForwardHealth(t.net, loss); err != nil {
+			return t.locateHealthError(err, state)
+		}
+	}
+
+	t.net.backward()
+
+	if t.config.checkNumericHealth {
+		if err := checkBackwardHealth(t.net); err != nil {
+			return t.locateHealthError(err, state)
+		}
+	}
+
+	var trainables []trainable = t.net.trainables()
+	var gradientNorm float64 = t.clipping.apply(trainables)
+	t.optimizer.step(trainables)
+
+	if t.config.checkNumericHealth {
+		if err := checkParameterHealth(t.net); err != nil {
+			return t.locateHealthError(err, state)
+		}
+	}
+
+	state.loss = loss
+	state.metrics["loss"] = loss
+	state.metrics["accuracy"] = accuracy
+	state.metrics["grad_norm"] = gradientNorm
+	return nil
+}
+
+func (t *trainer) locateHealthError(err *numericHealthError, state *trainingState) error {
+	err.epoch = state.epoch
+	err.batch = state.batch
+	return err
+}
+
+func (t *trainer) runCallbacks(hook func(c callback, state *trainingState) error, state *trainingState) error {
+	for _, c := range t.callbacks {
+		if err := hook(c, state); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// train runs epochs over inputs and targets until config.epochs epochs have been completed in total, stopping
+// early if a callback asks it to. Calling train again after raising config.epochs continues where it stopped.
+func (t *trainer) train(inputs matrix, targets []int) error {
+	if len(inputs) == 0 {
+		panic("Can not train on empty input batch")
+	}
+
+	if len(inputs) != len(targets) {
+		panic(fmt.Sprintf("Training targets length %d does not match input batch size %d", len(targets), len(inputs)))
+	}
+
+	return t.run(len(t.batchRanges(len(inputs))), func() (batchSource, error) {
+		var order []int = t.sampleOrder(len(inputs))
+		var ranges [][2]int = t.batchRanges(len(inputs))
+		var batchIndex int = 0
+
+		return func() (matrix, []int, error) {
+			if batchIndex == len(ranges) {
+				return nil, nil, io.EOF
+			}
+
+			var batchRange [2]int = ranges[batchIndex]
+			batchIndex++
+
+			var batchInputs matrix = make(matrix, 0, batchRange[1]-batchRange[0])
+			var batchTargets []int = make([]int, 0, batchRange[1]-batchRange[0])
+			for _, sampleIndex := range order[batchRange[0]:batchRange[1]] {
+				batchInputs = append(batchInputs, inputs[sampleIndex])
+				batchTargets = append(batchTargets, targets[sampleIndex])
+			}
+
+			return batchInputs, batchTargets, nil
+		}, nil
+	})
+}
+
+// trainStream is train for a dataset that is read again for every epoch instead of being held in memory. It needs
+// a positive batch size and shuffles through a buffer of config.shuffleBufferSize samples.
+func (t *trainer) trainStream(source dataset) error {
+	var shuffleBufferSize int = t.streamShuffleBufferSize()
+
+	var firstEpoch bool = true
+	return t.run(0, func() (batchSource, error) {
+		if !firstEpoch {
+			if err := source.reset(); err != nil {
+				return nil, err
+			}
+		}
+		firstEpoch = false
+
+		return newBatchStream(source, t.config.batchSize, shuffleBufferSize, t.random).nextBatch, nil
+	})
+}
+
+// trainPipeline is trainStream with batches read, shuffled and preprocessed on background goroutines while the
+// network trains on the current batch. Cancelling ctx stops training with the context's error.
+func (t *trainer) trainPipeline(ctx context.Context, source dataset, pipeline pipelineConfig) error {
+	var shuffleBufferSize int = t.streamShuffleBufferSize()
+	var current *prefetcher
+	defer func() {
+		if current != nil {
+			current.close()
+		}
+	}()
+
+	var firstEpoch bool = true
+	return t.run(0, func() (batchSource, error) {
+		if current != nil {
+			current.close()
+			current = nil
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		if !firstEpoch {
+			if err := source.reset(); err != nil {
+				return nil, err
+			}
+		}
+		firstEpoch = false
+
+		current = newPrefetcher(ctx, newBatchStream(source, t.config.batchSize, shuffleBufferSize, t.random), pipeline)
+		return current.nextBatch, nil
+	})
+}
+
+// streamShuffleBufferSize checks the config can stream batches and returns the shuffle buffer size to use.
+func (t *trainer) streamShuffleBufferSize() int {
+	if t.config.batchSize <= 0 {
+		panic(fmt.Sprintf("Can not stream training data with batch size %d", t.config.batchSize))
+	}
+
+	if !t.config.shuffle {
+		return 0
+	}
+
+	if t.config.shuffleBufferSize <= 0 {
+		return defaultShuffleBufferSize
+	}
+
+	return t.config.shuffleBufferSize
+}
+
+// batchSource returns the batches of one epoch and io.EOF after the last one.
+type batchSource func() (matrix, []int, error)
+
+// run trains on the batches of a new batchSource every epoch.
+func (t *trainer) run(batchesPerEpoch int, epochBatches func() (batchSource, error)) error {
+	var state *trainingState = &trainingState{net: t.net, batchesPerEpoch: batchesPerEpoch, metrics: map[string]float64{}, learningRate: t.optimizer.learningRate}
+
+	if err := t.runCallbacks(callback.onTrainBegin, state); err != nil {
+		return err
+	}
+
+	if t.config.resume && t.config.checkpointPath != "" {
+		if err := t.resumeFromCheckpoint(t.config.checkpointPath); err != nil {
+			return err
+		}
+
+		for _, c := range t.callbacks {
+			if stopping, ok := c.(stoppingCallback); ok && stopping.stoppedTraining() {
+				state.stopTraining = true
+			}
+		}
+	}
+
+	for t.completedEpochs < t.config.epochs && !state.stopTraining {
+		state.epoch = t.completedEpochs + 1
+		t.optimizer.learningRate = t.scheduler.learningRate(state.epoch)
+		state.learningRate = t.optimizer.learningRate
+
+		if err := t.runCallbacks(callback.onEpochBegin, state); err != nil {
+			return err
+		}
+
+		nextBatch, err := epochBatches()
+		if err != nil {
+			return err
+		}
+
+		var sampleCount int = 0
+		var epochLoss float64 = 0
+		var epochAccuracy float64 = 0
+		var epochGradientNorm float64 = 0
+
+		for batchIndex := 0; ; batchIndex++ {
+			batchInputs, batchTargets, err := nextBatch()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+
+			state.batch = batchIndex + 1
+			if err := t.runCallbacks(callback.onBatchBegin, state); err != nil {
+				return err
+			}
+
+			if err := t.trainBatch(batchInputs, batchTargets, state); err != nil {
+				return err
+			}
+
+			sampleCount += len(batchInputs)
+			epochLoss += state.metrics["loss"] * float64(len(batchInputs))
+			epochAccuracy += state.metrics["accuracy"] * float64(len(batchInputs))
+			epochGradientNorm = math.Max(epochGradientNorm, state.metrics["grad_norm"])
+
+			if err := t.runCallbacks(callback.onBatchEnd, state); err != nil {
+				return err
+			}
+		}
+
+		if sampleCount == 0 {
+			return fmt.Errorf("epoch %d has no training samples", state.epoch)
+		}
+
+		epochLoss /= float64(sampleCount)
+		epochAccuracy /= float64(sampleCount)
+
+		state.loss = epochLoss
+		state.metrics["loss"] = epochLoss
+		state.metrics["accuracy"] = epochAccuracy
+		state.metrics["grad_norm"] = epochGradientNorm
+
+		if t.validationInputs != nil {
+			state.metrics[validationMetricPrefix+"loss"] = t.net.forward(t.validationInputs, t.validationTargets)
+			state.metrics[validationMetricPrefix+"accuracy"] = calculateAccuracy(t.net.lastOutput, t.validationTargets)
+		}
+
+		if err := t.runCallbacks(callback.onEpochEnd, state); err != nil {
+			return err
+		}
+
+		t.completedEpochs = state.epoch
+		if t.config.checkpointPath != "" && t.completedEpochs%t.config.checkpointRate == 0 {
+			if err := t.saveCheckpoint(t.config.checkpointPath); err != nil {
+				return err
+			}
+		}
+	}
+
+	// The last epochs are checkpointed even if training ended between two checkpoints
+	if t.config.checkpointPath != "" && t.completedEpochs%t.config.checkpointRate != 0 {
+		if err := t.saveCheckpoint(t.config.checkpointPath); err != nil {
+			return err
+		}
+	}
+
+	return t.runCallbacks(callback.onTrainEnd, state)
+}
+
+func argmax(values vector) int {
+	var maxIndex int = 0
+
+	for index, value := range values {
+		if value > values[maxIndex] {
+			maxIndex = index
+		}
+	}
+
+	return maxIndex
+}
+
+func calculateAccuracy(output matrix, targets []int) float64 {
+	if len(output) == 0 {
+		return 0
+	}
+
+	var correct int = 0
+	for index, row := range output {
+		if argmax(row) == targets[index] {
+			correct++
+		}
+	}
+
+	return float64(correct) / float64(len(output))
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trainingTestData() (matrix, []int) {
+	var inputs matrix = matrix{
+		{0.1, 0.9, 0.2, 0.1},
+		{0.2, 0.8, 0.1, 0.0},
+		{0.9, 0.1, 0.7, 0.8},
+		{0.8, 0.2, 0.9, 0.9},
+		{0.5, 0.5, 0.4, 0.3},
+		{0.4, 0.6, 0.5, 0.4},
+	}
+
+	return inputs, []int{0, 0, 1, 1, 2, 2}
+}
+
+func newTrainingTestNetwork(seed int64) network {
+	rand.Seed(seed)
+	var l1 layer = newLayer(6, 4)
+	var l2 layer = newLayer(3, 6)
+
+	return newNetwork(&crossentropy{}, &l1, &reluActivation{}, &l2, &softmax{})
+}
+
+type recordingCallback struct {
+	baseCallback
+	events []string
+	err    error
+}
+
+func (r *recordingCallback) onTrainBegin(state *trainingState) error {
+	r.events = append(r.events, "trainBegin")
+	return nil
+}
+
+func (r *recordingCallback) onTrainEnd(state *trainingState) error {
+	r.events = append(r.events, "trainEnd")
+	return nil
+}
+
+func (r *recordingCallback) onEpochBegin(state *trainingState) error {
+	r.events = append(r.events, "epochBegin")
+	return nil
+}
+
+func (r *recordingCallback) onEpochEnd(state *trainingState) error {
+	r.events = append(r.events, "epochEnd")
+	return r.err
+}
+
+func (r *recordingCallback) onBatchBegin(state *trainingState) error {
+	r.events = append(r.events, "batchBegin")
+	return nil
+}
+
+func (r *recordingCallback) onBatchEnd(state *trainingState) error {
+	r.events = append(r.events, "batchEnd")
+	return nil
+}
+
+func TestNewTrainerPanics(t *testing.T) {
+	var net network = newTrainingTestNetwork(1)
+
+	assert.Panics(t, func() { newTrainer(&net, trainingConfig{epochs: 0}) }, "Should panic with 0 epochs")
+	assert.Panics(t, func() { newTrainer(&net, trainingConfig{epochs: 1, batchSize: -1}) }, "Should panic with negative batch size")
+
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 1})
+	assert.Panics(t, func() { tr.train(matrix{}, []int{}) }, "Should panic with empty inputs")
+	assert.Panics(t, func() { tr.train(matrix{{1, 2, 3, 4}}, []int{0, 1}) }, "Should panic with targets not matching inputs")
+	assert.Panics(t, func() { tr.setValidationData(matrix{}, []int{}) }, "Should panic with empty validation inputs")
+	assert.Panics(t, func() { tr.setValidationData(matrix{{1, 2, 3, 4}}, []int{}) }, "Should panic with validation targets not matching inputs")
+}
+
+// TestTrainerMatchesManualLoop checks the trainer against the hand written loop main.go used before the trainer existed.
+func TestTrainerMatchesManualLoop(t *testing.T) {
+	const epochs int = 50
+	const learningRateStart float64 = 1
+	const learningRateDecay float64 = 0.001
+	var inputs, targets = trainingTestData()
+
+	var manual network = newTrainingTestNetwork(4)
+	var l1 *layer = manual.components[0].(*layer)
+	var l2 *layer = manual.components[2].(*layer)
+	var manualLosses []float64
+
+	for i := 0; i < epochs; i++ {
+		var learningRate float64 = learningRateStart * (1 / (1 + learningRateDecay*float64(i+1)))
+		manualLosses = append(manualLosses, manual.forward(inputs, targets))
+		manual.backward()
+
+		for _, l := range []*layer{l1, l2} {
+			for index := range l.neurons {
+				var n *neuron = &l.neurons[index]
+				for weightIndex, derivativeValue := range n.derivativeWeights {
+					n.weights[weightIndex] = n.weights[weightIndex] + (-1 * derivativeValue * learningRate)
+				}
+				n.bias = n.bias + (-1 * n.derivativeBias * learningRate)
+			}
+		}
+	}
+
+	var trained network = newTrainingTestNetwork(4)
+	var history *lossHistoryCallback = &lossHistoryCallback{}
+	var tr trainer = newTrainer(&trained, trainingConfig{epochs: epochs, learningRateStart: learningRateStart, learningRateDecay: learningRateDecay}, history)
+
+	require.NoError(t, tr.train(inputs, targets))
+	require.Equal(t, manualLosses, history.epochLosses, "Trainer should follow the same loss trajectory as the manual loop")
+	require.Equal(t, l1.getParameters(), trained.components[0].(*layer).getParameters(), "Trainer should produce the same weights as the manual loop")
+}
+
+func TestTrainerCallbackOrder(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var recorder *recordingCallback = &recordingCallback{}
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 2, batchSize: 4, learningRateStart: 0.1}, recorder)
+
+	require.NoError(t, tr.train(inputs, targets))
+	require.Equal(t, []string{
+		"trainBegin",
+		"epochBegin", "batchBegin", "batchEnd", "batchBegin", "batchEnd", "epochEnd",
+		"epochBegin", "batchBegin", "batchEnd", "batchBegin", "batchEnd", "epochEnd",
+		"trainEnd",
+	}, recorder.events, "Callbacks were called in the wrong order")
+}
+
+func TestTrainerCallbackErrorAbortsTraining(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var failure error = errors.New("callback failure")
+	var recorder *recordingCallback = &recordingCallback{err: failure}
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 5, learningRateStart: 0.1}, recorder)
+
+	require.Equal(t, failure, tr.train(inputs, targets), "Trainer should return the callback error")
+	require.Equal(t, []string{"trainBegin", "epochBegin", "batchBegin", "batchEnd", "epochEnd"}, recorder.events, "Training should stop at the failing hook")
+}
+
+func TestTrainerShuffleIsSeeded(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var run func(seed int64) []float64 = func(seed int64) []float64 {
+		var net network = newTrainingTestNetwork(2)
+		var history *lossHistoryCallback = &lossHistoryCallback{}
+		var tr trainer = newTrainer(&net, trainingConfig{epochs: 5, batchSize: 2, learningRateStart: 0.1, shuffle: true, seed: seed}, history)
+		require.NoError(t, tr.train(inputs, targets))
+		return history.batchLosses
+	}
+
+	require.Equal(t, run(3), run(3), "Training with the same seed should be deterministic")
+	require.NotEqual(t, run(3), run(4), "Training with different seeds should shuffle differently")
+}
+
+func TestCalculateAccuracy(t *testing.T) {
+	var output matrix = matrix{{0.1, 0.9}, {0.8, 0.2}, {0.3, 0.7}, {0.6, 0.4}}
+
+	require.Equal(t, 0.75, calculateAccuracy(output, []int{1, 0, 1, 1}), "Wrong accuracy")
+	require.Equal(t, 0.0, calculateAccuracy(matrix{}, []int{}), "Accuracy of an empty batch should be 0")
+}
+
+func TestTrainerValidationMetrics(t *testing.T) {
+	var inputs, targets = trainingTestData()
+	var net network = newTrainingTestNetwork(1)
+	var recorder *metricsRecorder = &metricsRecorder{}
+	var tr trainer = newTrainer(&net, trainingConfig{epochs: 2, learningRateStart: 0.1}, recorder)
+	tr.setValidationData(inputs[:2], targets[:2])
+
+	require.NoError(t, tr.train(inputs, targets))
+	require.Contains(t, recorder.last, "val_loss", "Validation loss should be reported")
+	require.Contains(t, recorder.last, "val_accuracy", "Validation accuracy should be reported")
+	require.Equal(t, net.forward(inputs[:2], targets[:2]), recorder.last["val_loss"], "Validation loss should be measured after the epoch")
+}
+
+type metricsRecorder struct {
+	baseCallback
+	last map[string]float64
+}
+
+func (m *metricsRecorder) onEpochEnd(state *trainingState) error {
+	m.last = map[string]float64{}
+	for name, value := range state.metrics {
+		m.last[name] = value
+	}
+
+	return nil
+}
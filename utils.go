@@ -1,6 +1,9 @@
 package main
 
-import "math/rand"
+import (
+	"math"
+	"math/rand"
+)
 
 type vector []float64
 type matrix []vector
@@ -9,6 +12,15 @@ func randRangeFloat64(min, max float64) float64 {
 	return min + rand.Float64()*(max-min)
 }
 
+// randRangeFloat64From is randRangeFloat64 drawing from random, or from the global source when random is nil.
+func randRangeFloat64From(random *rand.Rand, min, max float64) float64 {
+	if random == nil {
+		return randRangeFloat64(min, max)
+	}
+
+	return min + random.Float64()*(max-min)
+}
+
 func clip(min, max, value float64) float64 {
 	if value < min {
 		return min
@@ -29,3 +41,43 @@ func vectorSum(vec vector) float64 {
 
 	return sum
 }
+
+func vectorMax(vec vector) float64 {
+	var max float64 = math.Inf(-1)
+
+	for _, value := range vec {
+		if value > max {
+			max = value
+		}
+	}
+
+	return max
+}
+
+func vectorMin(vec vector) float64 {
+	var min float64 = math.Inf(1)
+
+	for _, value := range vec {
+		if value < min {
+			min = value
+		}
+	}
+
+	return min
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}